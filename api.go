@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const apiPrefix = "/api/v1"
+
+type apiFile struct {
+	File    string `json:"file"`
+	Methods int    `json:"methods"`
+}
+
+type apiMethod struct {
+	Method string `json:"method"`
+	Name   string `json:"name"`
+	Start  int    `json:"start"` // Primera línea, desde 1
+	End    int    `json:"end"`   // Última línea incluida
+}
+
+type apiValues struct {
+	File     string            `json:"file"`
+	Method   string            `json:"method"`
+	Values   map[string]string `json:"values"`
+	Versions map[string]int    `json:"versions"`
+}
+
+type apiValue struct {
+	File    string `json:"file"`
+	Method  string `json:"method"`
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Version *int   `json:"version,omitempty"` // Si se envía, el cambio falla con 409 si el valor ya cambió
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(logOutput, "Error encoding API response: %v\n", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func getAPIMethods(data fileData) []apiMethod {
+	methods := make([]apiMethod, 0, len(data.Methods))
+	for i, line := range data.Methods {
+		methods = append(methods, apiMethod{
+			Method: data.Content[line],
+			Name:   extractMethodName(data.Content[line]),
+			Start:  line + 1,
+			End:    line + len(data.getMethodSegment(i)),
+		})
+	}
+	return methods
+}
+
+// findAPIMethod validates the file and method of a request.
+func findAPIMethod(w http.ResponseWriter, file string, method string) (fileData, bool) {
+	data, ok := findFileData(file)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "file not found: "+file)
+		return fileData{}, false
+	}
+	if data.getMethodHash(method) == "" {
+		writeJSONError(w, http.StatusNotFound, "method not found: "+method)
+		return fileData{}, false
+	}
+	return data, true
+}
+
+func apiFilesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	files := make([]apiFile, 0, len(projectFiles))
+	for _, filepath := range projectFiles {
+		filename := getFilename(filepath)
+		files = append(files, apiFile{filename, len(filesData[filename].Methods)})
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func apiMethodsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	file := r.URL.Query().Get("file")
+	data, ok := findFileData(file)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "file not found: "+file)
+		return
+	}
+	writeJSON(w, http.StatusOK, getAPIMethods(data))
+}
+
+func apiValuesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		data, ok := findAPIMethod(w, query.Get("file"), query.Get("method"))
+		if !ok {
+			return
+		}
+
+		values := apiValues{
+			File:     getFilename(data.Filename),
+			Method:   query.Get("method"),
+			Values:   make(map[string]string),
+			Versions: make(map[string]int),
+		}
+		for _, field := range configProject.UserFields {
+			userField, _ := getUserField(data.Filename, values.Method, field.Name)
+			values.Values[field.Name] = userField.Value
+			values.Versions[field.Name] = userField.Version
+		}
+		writeJSON(w, http.StatusOK, values)
+
+	case http.MethodPut, http.MethodPost:
+		var value apiValue
+		if err := json.NewDecoder(r.Body).Decode(&value); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+
+		data, ok := findAPIMethod(w, value.File, value.Method)
+		if !ok {
+			return
+		}
+		if _, ok := getUserFieldConfig(value.Field); !ok {
+			writeJSONError(w, http.StatusBadRequest, "unknown field: "+value.Field)
+			return
+		}
+		version := anyVersion
+		if value.Version != nil {
+			version = *value.Version
+		}
+		current, err := updateUserField(createFieldName(data.Filename, value.Method, value.Field), value.Value, getRequestReviewer(r), version)
+		if err == errStaleValue {
+			writeJSON(w, http.StatusConflict, saveResult{err.Error(), current.Value, current.Version, current.Reviewer})
+			return
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid value for field "+value.Field+": "+value.Value)
+			return
+		}
+		value.File = getFilename(data.Filename)
+		value.Version = &current.Version
+		writeJSON(w, http.StatusOK, value)
+
+	case http.MethodDelete:
+		query := r.URL.Query()
+		data, ok := findAPIMethod(w, query.Get("file"), query.Get("method"))
+		if !ok {
+			return
+		}
+		if _, ok := getUserFieldConfig(query.Get("field")); !ok {
+			writeJSONError(w, http.StatusBadRequest, "unknown field: "+query.Get("field"))
+			return
+		}
+		oldValue := getUserValue(data.Filename, query.Get("method"), query.Get("field"))
+		if clearUserValue(data.Filename, query.Get("method"), query.Get("field")) {
+			recordChange(getRequestReviewer(r), data.Filename, query.Get("method"), query.Get("field"), oldValue, "")
+			markChanged()
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
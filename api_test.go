@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setupAPIProject() *http.ServeMux {
+	setupTestProject("/test/project/", []UserField{
+		{Name: "Checked", Type: EnumBoolean},
+		{Name: "Status", Type: EnumChoice, Options: []string{"todo", "done"}},
+	}, testFile{"main.go", []string{"package main", "func a() {", "}", "func b() {", "\treturn", "}"}})
+	setUserFields([]fieldsData{
+		{Filename: "/test/project/main.go", Method: "func a() {", Field: "Checked", Value: "1"},
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiPrefix+"/files", apiFilesHandler)
+	mux.HandleFunc(apiPrefix+"/methods", apiMethodsHandler)
+	mux.HandleFunc(apiPrefix+"/values", apiValuesHandler)
+	return mux
+}
+
+func apiRequest(mux *http.ServeMux, method string, target string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return recorder
+}
+
+func TestAPIListFilesAndMethods(t *testing.T) {
+	mux := setupAPIProject()
+
+	recorder := apiRequest(mux, http.MethodGet, apiPrefix+"/files", "")
+	var files []apiFile
+	if err := json.Unmarshal(recorder.Body.Bytes(), &files); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(files) != 1 || files[0] != (apiFile{"main.go", 2}) {
+		t.Errorf("Unexpected files: %+v", files)
+	}
+
+	recorder = apiRequest(mux, http.MethodGet, apiPrefix+"/methods?file=main.go", "")
+	var methods []apiMethod
+	if err := json.Unmarshal(recorder.Body.Bytes(), &methods); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	expected := []apiMethod{
+		{Method: "func a() {", Name: "a", Start: 2, End: 3},
+		{Method: "func b() {", Name: "b", Start: 4, End: 6},
+	}
+	if len(methods) != len(expected) {
+		t.Fatalf("Unexpected methods: %+v", methods)
+	}
+	for i := range expected {
+		if methods[i] != expected[i] {
+			t.Errorf("Method %d = %+v; expected %+v", i, methods[i], expected[i])
+		}
+	}
+
+	if recorder := apiRequest(mux, http.MethodGet, apiPrefix+"/methods?file=missing.go", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Missing file returned %d", recorder.Code)
+	}
+}
+
+func TestAPIValues(t *testing.T) {
+	mux := setupAPIProject()
+	query := apiPrefix + "/values?file=main.go&method=" + "func%20a()%20%7B"
+
+	recorder := apiRequest(mux, http.MethodGet, query, "")
+	var values apiValues
+	if err := json.Unmarshal(recorder.Body.Bytes(), &values); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if values.Values["Checked"] != "1" || values.Values["Status"] != "" || len(values.Values) != 2 {
+		t.Errorf("Unexpected values: %+v", values)
+	}
+
+	tests := []struct {
+		body     string
+		expected int
+	}{
+		{`{"file":"main.go","method":"func a() {","field":"Status","value":"done"}`, http.StatusOK},
+		{`{"file":"main.go","method":"func a() {","field":"Status","value":"maybe"}`, http.StatusBadRequest},
+		{`{"file":"main.go","method":"func a() {","field":"Unknown","value":"x"}`, http.StatusBadRequest},
+		{`{"file":"main.go","method":"func z() {","field":"Status","value":"done"}`, http.StatusNotFound},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		if recorder := apiRequest(mux, http.MethodPut, apiPrefix+"/values", test.body); recorder.Code != test.expected {
+			t.Errorf("PUT %s = %d; expected %d", test.body, recorder.Code, test.expected)
+		}
+	}
+	if value := getUserValue("/test/project/main.go", "func a() {", "Status"); value != "done" {
+		t.Errorf("Status = %q; expected done", value)
+	}
+
+	if recorder := apiRequest(mux, http.MethodDelete, query+"&field=Checked", ""); recorder.Code != http.StatusNoContent {
+		t.Errorf("DELETE returned %d", recorder.Code)
+	}
+	if value := getUserValue("/test/project/main.go", "func a() {", "Checked"); value != "" {
+		t.Errorf("Checked = %q after DELETE; expected empty", value)
+	}
+
+	if recorder := apiRequest(mux, http.MethodPatch, query, ""); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PATCH returned %d", recorder.Code)
+	}
+}
+
+func TestAPIValuesConflict(t *testing.T) {
+	mux := setupAPIProject()
+	stale := `{"file":"main.go","method":"func a() {","field":"Checked","value":"0","version":0}`
+
+	// Los valores guardados antes de las versiones tienen la versión 0
+	recorder := apiRequest(mux, http.MethodPut, apiPrefix+"/values", stale)
+	var value apiValue
+	if err := json.Unmarshal(recorder.Body.Bytes(), &value); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s", recorder.Code, recorder.Body.String())
+	}
+	if value.Version == nil || *value.Version != 1 {
+		t.Errorf("Expected version 1, got %+v", value)
+	}
+
+	recorder = apiRequest(mux, http.MethodPut, apiPrefix+"/values", stale)
+	if recorder.Code != http.StatusConflict || !strings.Contains(recorder.Body.String(), `"version":1`) {
+		t.Errorf("Stale PUT = %d %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Recursos del frontend embebidos para funcionar sin conexión
+//
+//go:embed assets
+var assetsFS embed.FS
+
+func assetsHandler() http.Handler {
+	assets, err := fs.Sub(assetsFS, "assets")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/assets/", http.FileServer(http.FS(assets)))
+}
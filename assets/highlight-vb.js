@@ -0,0 +1,44 @@
+/*
+  Visual Basic 6 / VBA language for highlight.js, the bundled build doesn't
+  include "vbnet". Registered as "vb" with the aliases used in the configs.
+*/
+hljs.registerLanguage("vb", function (hljs) {
+  return {
+    name: "Visual Basic",
+    aliases: ["vb6", "vba", "vbnet", "bas", "frm", "cls"],
+    case_insensitive: true,
+    keywords: {
+      keyword:
+        "addressof and as attribute byref byval call case const declare dim do each else elseif " +
+        "end enum erase error event exit explicit for friend function get global gosub goto if " +
+        "implements in is let lib like loop me mod new next not of on option optional or " +
+        "paramarray preserve private property public raiseevent redim rem resume return select " +
+        "set static step stop sub then to type typeof until wend while with withevents xor",
+      built_in:
+        "boolean byte currency date decimal double integer long object single string variant " +
+        "cbool cbyte ccur cdate cdbl cint clng csng cstr cvar debug err format instr lbound " +
+        "lcase left len ltrim mid msgbox now right rtrim trim ubound ucase val",
+      literal: "true false nothing null empty"
+    },
+    contains: [
+      hljs.inherit(hljs.QUOTE_STRING_MODE, { contains: [{ begin: '""' }] }),
+      hljs.COMMENT("'", "$", { relevance: 0 }),
+      hljs.COMMENT("\\bRem\\b", "$", { relevance: 0 }),
+      {
+        className: "literal",
+        begin: "#\\d{1,2}/\\d{1,2}/\\d{2,4}( \\d{1,2}:\\d{2}(:\\d{2})?( [AP]M)?)?#"
+      },
+      {
+        className: "meta",
+        begin: "#",
+        end: "$",
+        keywords: { "meta-keyword": "if else elseif end region externalsource const" }
+      },
+      {
+        className: "number",
+        begin: "(&H[0-9A-F]+|&O[0-7]+|\\b\\d+(\\.\\d+)?(E[+-]?\\d+)?)[%&!#@]?",
+        relevance: 0
+      }
+    ]
+  };
+});
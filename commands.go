@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+)
+
+type command struct {
+	Usage       string
+	Description string
+	Run         func(args []string) int
+}
+
+var commands = map[string]command{
+	"export": {
+		Usage:       "export --path <project path> --out <folder>",
+		Description: "write the review as a static website with read-only fields",
+		Run:         exportCommand,
+	},
+	"mappings": {
+		Usage:       "mappings --path <project path> --target <migrated project path> [--accept]",
+		Description: "list legacy methods not linked to a target method, with suggestions",
+		Run:         mappingsCommand,
+	},
+	"stats": {
+		Usage:       "stats --path <project path> [--format text|json] [--field <boolean field> --min <percent>]",
+		Description: "print the completion of every field, failing when --field is below --min",
+		Run:         statsCommand,
+	},
+	"orphans": {
+		Usage:       "orphans --path <project path> [--file <file>] [--delete | --reassign <new file>]",
+		Description: "list stored field values whose file or method no longer exists",
+		Run:         orphansCommand,
+	},
+	"history": {
+		Usage:       "history --path <project path> [--file <file>] [--method <header>] [--format text|json]",
+		Description: "print the changes made to the field values, oldest first",
+		Run:         historyCommand,
+	},
+	"merge": {
+		Usage:       "merge --path <project path> [--policy newest,or,concat] [--dry-run] <user fields file>...",
+		Description: "merge the field values of other reviewers, keeping the conflicts to resolve at /conflicts",
+		Run:         mergeCommand,
+	},
+	"report": {
+		Usage:       "report --path <project path> [--format csv|md|html] [--columns <field>,...] [--out <file>]",
+		Description: "write every method with its field values as a spreadsheet, Markdown table or printable page",
+		Run:         reportCommand,
+	},
+	"import": {
+		Usage:       "import --path <project path> [--user <name>] [--unmatched <file>] [--dry-run] <csv file>",
+		Description: "set field values from a CSV with file, method, field and value columns, reporting the rows that do not match",
+		Run:         importCommand,
+	},
+}
+
+// runCommand executes a CLI subcommand and returns the process exit code.
+func runCommand(name string, args []string) int {
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintln(logOutput, "Unknown command:", name)
+		printUsage()
+		return 2
+	}
+	return cmd.Run(args)
+}
+
+func printUsage() {
+	fmt.Fprintln(logOutput, "Usage: zoomer --path <project path> [--port <port>] [--target <migrated project path>] [--user <name>]")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(logOutput, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(logOutput, "  zoomer %s\n      %s\n", commands[name].Usage, commands[name].Description)
+	}
+}
+
+// newCommandFlags creates the flag set of a command with the common --path flag.
+func newCommandFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&pathProject, "path", "", "project path")
+	return flags
+}
+
+// loadCommandProject loads the project the same way the server does. The
+// loading messages are written to logOutput.
+func loadCommandProject() bool {
+	if pathProject == "" || !isValidPath(pathProject) {
+		fmt.Fprintln(logOutput, "Invalid project path:", pathProject)
+		return false
+	}
+	if !loadProject() {
+		fmt.Fprintln(logOutput, "Error loading project")
+		return false
+	}
+	return true
+}
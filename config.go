@@ -6,16 +6,26 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"time"
 )
 
 const (
-	configFilename     = "zoomer-config.json"
-	userFieldsFilename = "zoomer-userfields.json"
+	configFilename         = "zoomer-config.json"
+	userFieldsFilename     = "zoomer-userfields.json"
+	mappingsFilename       = "zoomer-mappings.json"
+	historyFilename        = "zoomer-history.jsonl"
+	mergeConflictsFilename = "zoomer-merge-conflicts.json"
+	reanchorFilename       = "zoomer-reanchor-discarded.json"
+	serverLockFilename     = "zoomer-server.lock"
+
+	defaultSaveInterval = 30 * time.Second
 )
 
 var (
-	configProject        config
-	methodFilterRegexes []*regexp.Regexp
+	configProject             config
+	methodFilterRegexes       []*regexp.Regexp
+	targetMethodFilterRegexes []*regexp.Regexp
+	saveInterval              = defaultSaveInterval
 )
 
 type EnumFieldType string
@@ -23,34 +33,88 @@ type EnumFieldType string
 const (
 	EnumTextBox EnumFieldType = "textbox"
 	EnumBoolean EnumFieldType = "boolean"
+	EnumChoice  EnumFieldType = "choice"
 )
 
 type UserField struct {
-	Name string
-	Type EnumFieldType
+	Name    string
+	Type    EnumFieldType
+	Options []string `json:",omitempty"`
+}
+
+// isValidValue reports whether value can be stored in the field.
+// Choice fields only accept one of their options (or empty to clear it).
+func (u UserField) isValidValue(value string) bool {
+	if u.Type != EnumChoice || value == "" {
+		return true
+	}
+	for _, option := range u.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+func getUserFieldConfig(name string) (UserField, bool) {
+	for _, field := range configProject.UserFields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return UserField{}, false
+}
+
+// targetConfig holds the filters of the migrated tree (--target)
+type targetConfig struct {
+	LangHighlight string   `json:"lang_highlight"`
+	ExtFilter     []string `json:"ext_filter"`
+	MethodFilter  []string `json:"method_filter"`
+	Include       []string `json:"include,omitempty"`
+	Exclude       []string `json:"exclude,omitempty"`
+	GitIgnore     bool     `json:"gitignore,omitempty"`
 }
 
 type config struct {
-	ProjectName   string      `json:"project_name"`
-	LangHighlight string      `json:"lang_highlight"`
-	ExtFilter     []string    `json:"ext_filter"`
-	MethodFilter  []string    `json:"method_filter"`
-	UserFields    []UserField `json:"user_fields"`
+	ProjectName   string                 `json:"project_name"`
+	LangHighlight string                 `json:"lang_highlight"`
+	ExtFilter     []string               `json:"ext_filter"`
+	MethodFilter  []string               `json:"method_filter"`
+	UserFields    []UserField            `json:"user_fields"`
+	Target        *targetConfig          `json:"target,omitempty"`
+	SaveInterval  string                 `json:"save_interval,omitempty"` // Duración como "30s", "0" guarda en cada cambio
+	Languages     map[string]langProfile `json:"languages,omitempty"`     // Perfiles por extensión, como ".sql"
+	Include       []string               `json:"include,omitempty"`       // Patrones como "src/**/*.bas"
+	Exclude       []string               `json:"exclude,omitempty"`       // Patrones como "**/node_modules/"
+	GitIgnore     bool                   `json:"gitignore,omitempty"`
+}
+
+func compileMethodFilter(patterns []string) []*regexp.Regexp {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fmt.Fprintf(logOutput, "Warning: invalid regex pattern '%s': %v\n", pattern, err)
+			continue
+		}
+		regexes = append(regexes, re)
+	}
+	return regexes
 }
 
 func createConfig() bool {
-	fmt.Println("Creating config file:", path.Join(pathProject, configFilename))
+	fmt.Fprintln(logOutput, "Creating config file:", path.Join(pathProject, configFilename))
 	newConfig := config{
 		ProjectName:   "New Project",
 		LangHighlight: "go",
 		ExtFilter:     []string{".go"},
 		MethodFilter:  []string{"func (\\(.*\\))?(.*)\\(.*?\\).*{"},
-		UserFields:    []UserField{{"Checked", EnumBoolean}},
+		UserFields:    []UserField{{Name: "Checked", Type: EnumBoolean}},
 	}
 
 	configFile, err := os.Create(path.Join(pathProject, configFilename))
 	if err != nil {
-		fmt.Printf("Error creating config file: %v\n", err)
+		fmt.Fprintf(logOutput, "Error creating config file: %v\n", err)
 		return false
 	}
 	defer configFile.Close()
@@ -59,20 +123,20 @@ func createConfig() bool {
 	encoder.SetIndent("", "    ")
 	err = encoder.Encode(newConfig)
 	if err != nil {
-		fmt.Printf("Error encoding config: %v\n", err)
+		fmt.Fprintf(logOutput, "Error encoding config: %v\n", err)
 		return false
 	}
 
-	fmt.Println("Config file created successfully")
+	fmt.Fprintln(logOutput, "Config file created successfully")
 	return true
 }
 
 func loadConfig() bool {
 	configPath := path.Join(pathProject, configFilename)
 	if !isValidFile(configPath) {
-		fmt.Println("Config file not found, creating new one...")
+		fmt.Fprintln(logOutput, "Config file not found, creating new one...")
 		if !createConfig() {
-			fmt.Println("Failed to create config file")
+			fmt.Fprintln(logOutput, "Failed to create config file")
 			return false
 		}
 		return false
@@ -80,7 +144,7 @@ func loadConfig() bool {
 
 	configFile, err := os.Open(configPath)
 	if err != nil {
-		fmt.Printf("Error opening config file: %v\n", err)
+		fmt.Fprintf(logOutput, "Error opening config file: %v\n", err)
 		return false
 	}
 	defer configFile.Close()
@@ -88,21 +152,39 @@ func loadConfig() bool {
 	decoder := json.NewDecoder(configFile)
 	err = decoder.Decode(&configProject)
 	if err != nil {
-		fmt.Printf("Error decoding config file: %v\n", err)
+		fmt.Fprintf(logOutput, "Error decoding config file: %v\n", err)
 		return false
 	}
 
 	// Precompilar expresiones regulares para mejor rendimiento
-	methodFilterRegexes = make([]*regexp.Regexp, 0, len(configProject.MethodFilter))
-	for _, pattern := range configProject.MethodFilter {
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			fmt.Printf("Warning: invalid regex pattern '%s': %v\n", pattern, err)
-			continue
+	methodFilterRegexes = compileMethodFilter(configProject.MethodFilter)
+	if configProject.Target != nil {
+		targetMethodFilterRegexes = compileMethodFilter(configProject.Target.MethodFilter)
+	}
+	compileLangProfiles()
+
+	saveInterval = parseSaveInterval(configProject.SaveInterval)
+
+	for _, field := range configProject.UserFields {
+		if field.Type == EnumChoice && len(field.Options) == 0 {
+			fmt.Fprintf(logOutput, "Warning: choice field '%s' has no options\n", field.Name)
 		}
-		methodFilterRegexes = append(methodFilterRegexes, re)
 	}
 
-	fmt.Printf("Config loaded successfully: %s\n", configProject.ProjectName)
+	fmt.Fprintf(logOutput, "Config loaded successfully: %s\n", configProject.ProjectName)
 	return true
 }
+
+// parseSaveInterval reads the save_interval of the config. Zero saves on every
+// change; an empty or invalid value keeps the default.
+func parseSaveInterval(value string) time.Duration {
+	if value == "" {
+		return defaultSaveInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		fmt.Fprintf(logOutput, "Warning: invalid save_interval '%s', using %v\n", value, defaultSaveInterval)
+		return defaultSaveInterval
+	}
+	return interval
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSaveInterval(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", defaultSaveInterval},
+		{"0", 0},
+		{"5s", 5 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"-1s", defaultSaveInterval},
+		{"soon", defaultSaveInterval},
+	}
+
+	for _, test := range tests {
+		if interval := parseSaveInterval(test.value); interval != test.expected {
+			t.Errorf("parseSaveInterval(%q) = %v; expected %v", test.value, interval, test.expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// getExportPage returns the options of the pages of a static export. root
+// is the relative path from the page to the index.
+func getExportPage(root string) pageOptions {
+	return pageOptions{
+		Root:     root,
+		Index:    root + "index.html",
+		ReadOnly: true,
+		FileLink: func(filename string) string {
+			return root + "files/" + strings.TrimPrefix(getFileURL(filename), "/file/") + ".html"
+		},
+	}
+}
+
+// getExportFilename returns where the page of a file is written: files/
+// keeps the folders of the project, so two files never share a page.
+func getExportFilename(filename string) string {
+	return path.Join("files", strings.TrimPrefix(filename, "/")) + ".html"
+}
+
+// exportSite writes the whole review as a self-contained static website.
+func exportSite(outDir string) error {
+	if err := exportAssets(outDir); err != nil {
+		return err
+	}
+
+	if err := exportPage(filepath.Join(outDir, "index.html"), func(w io.Writer) {
+		showFileIndexHtml(w, getExportPage(""))
+	}); err != nil {
+		return err
+	}
+
+	for _, source := range projectFiles {
+		filename := getFilename(source)
+		target := getExportFilename(filename)
+		page := getExportPage(strings.Repeat("../", strings.Count(target, "/")))
+		page.Current = filename
+
+		target = filepath.Join(outDir, filepath.FromSlash(target))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := exportPage(target, func(w io.Writer) {
+			showFilePageHtml(w, source, page)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportPage(filename string, render func(w io.Writer)) error {
+	var buf bytes.Buffer
+	render(&buf)
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func exportAssets(outDir string) error {
+	return fs.WalkDir(assetsFS, "assets", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(outDir, filepath.FromSlash(name))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		data, err := assetsFS.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+}
+
+func exportCommand(args []string) int {
+	var outDir string
+
+	flags := newCommandFlags("export")
+	flags.StringVar(&outDir, "out", "", "output folder")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if outDir == "" {
+		fmt.Fprintln(os.Stderr, "Missing output folder (--out)")
+		return 2
+	}
+
+	if !loadCommandProject() {
+		return 1
+	}
+
+	if err := exportSite(outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting site: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Site exported to %s: %d file(s)\n", outDir, len(projectFiles))
+	return 0
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportSite(t *testing.T) {
+	pathProject = setupStatsProject(t)
+	if !loadCommandProject() {
+		t.Fatal("loadCommandProject() failed")
+	}
+
+	outDir := t.TempDir()
+	if err := exportSite(outDir); err != nil {
+		t.Fatalf("exportSite() failed: %v", err)
+	}
+
+	for _, name := range []string{
+		"index.html",
+		"files/main.go.html",
+		"assets/highlight.min.js",
+		"assets/fonts/fonts.css",
+	} {
+		if !isValidFile(filepath.Join(outDir, name)) {
+			t.Errorf("Expected %s in the export", name)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if !strings.Contains(string(index), `href="files/main.go.html"`) {
+		t.Error("Index should link to the file pages")
+	}
+
+	page, err := os.ReadFile(filepath.Join(outDir, "files", "main.go.html"))
+	if err != nil {
+		t.Fatalf("Failed to read file page: %v", err)
+	}
+	content := string(page)
+	if !strings.Contains(content, `href="../assets/github-dark.css"`) {
+		t.Error("File pages should use relative assets")
+	}
+	if strings.Contains(content, "saveChange") {
+		t.Error("Exported fields should be read-only")
+	}
+	if !strings.Contains(content, `checked disabled>`) {
+		t.Error("Exported page should show the current field values")
+	}
+	if !strings.Contains(content, `disabled>todo</textarea>`) {
+		t.Error("Exported page should show the current notes")
+	}
+	if strings.Contains(content, "📁 "+parseEscapeHTML(pathProject)) {
+		t.Error("Exported page should not show the project folder")
+	}
+}
+
+func TestExportSiteKeepsFolders(t *testing.T) {
+	pathProject = setupStatsProject(t)
+	// Con "/" cambiado por "." las dos páginas serían files/a.b.go.html
+	if err := os.MkdirAll(filepath.Join(pathProject, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pathProject, "a", "b.go"), []byte("package a\nfunc One() {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pathProject, "a.b.go"), []byte("package main\nfunc Two() {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !loadCommandProject() {
+		t.Fatal("loadCommandProject() failed")
+	}
+
+	outDir := t.TempDir()
+	if err := exportSite(outDir); err != nil {
+		t.Fatalf("exportSite() failed: %v", err)
+	}
+
+	pages := []struct {
+		name   string
+		method string
+		href   string
+		assets string
+	}{
+		{"files/a/b.go.html", "func One() {", `href="files/a/b.go.html"`, `href="../../assets/github-dark.css"`},
+		{"files/a.b.go.html", "func Two() {", `href="files/a.b.go.html"`, `href="../assets/github-dark.css"`},
+	}
+	index, _ := os.ReadFile(filepath.Join(outDir, "index.html"))
+	for _, page := range pages {
+		content, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(page.name)))
+		if err != nil {
+			t.Errorf("Expected %s in the export: %v", page.name, err)
+			continue
+		}
+		if !strings.Contains(string(content), page.method) || !strings.Contains(string(content), page.assets) {
+			t.Errorf("%s should show %s with assets at %s", page.name, page.method, page.assets)
+		}
+		if !strings.Contains(string(index), page.href) {
+			t.Errorf("Index should link %s", page.href)
+		}
+	}
+}
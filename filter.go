@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// methodFilter reports whether a method of a file (full path) must be shown.
+type methodFilter func(filename string, method string) bool
+
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenEqual
+	tokenNotEqual
+	tokenOpen
+	tokenClose
+)
+
+type filterToken struct {
+	Kind filterTokenKind
+	Text string
+}
+
+// tokenizeFilter splits a filter expression. Words can be quoted with double
+// quotes to include spaces or operators.
+func tokenizeFilter(text string) ([]filterToken, error) {
+	tokens := make([]filterToken, 0)
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == ' ' || text[i] == '\t':
+			i++
+		case strings.HasPrefix(text[i:], "&&"):
+			tokens = append(tokens, filterToken{tokenAnd, "&&"})
+			i += 2
+		case strings.HasPrefix(text[i:], "||"):
+			tokens = append(tokens, filterToken{tokenOr, "||"})
+			i += 2
+		case strings.HasPrefix(text[i:], "!="):
+			tokens = append(tokens, filterToken{tokenNotEqual, "!="})
+			i += 2
+		case text[i] == '!':
+			tokens = append(tokens, filterToken{tokenNot, "!"})
+			i++
+		case text[i] == '=':
+			tokens = append(tokens, filterToken{tokenEqual, "="})
+			i++
+		case text[i] == '(':
+			tokens = append(tokens, filterToken{tokenOpen, "("})
+			i++
+		case text[i] == ')':
+			tokens = append(tokens, filterToken{tokenClose, ")"})
+			i++
+		case text[i] == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote at %d", i+1)
+			}
+			tokens = append(tokens, filterToken{tokenWord, text[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			start := i
+			for i < len(text) && !strings.ContainsRune(" \t&|!=()\"", rune(text[i])) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected %q at %d", text[i], i+1)
+			}
+			tokens = append(tokens, filterToken{tokenWord, text[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek(kind filterTokenKind) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].Kind == kind
+}
+
+// parseFilter compiles an expression like `!Checked || Status=todo`.
+//
+//	expr   = and { "||" and }
+//	and    = unary { "&&" unary }
+//	unary  = "!" unary | "(" expr ")" | Field [ ("=" | "!=") value ]
+//
+// A bare field matches when it is done (checked or not empty).
+func parseFilter(text string) (methodFilter, error) {
+	tokens, err := tokenizeFilter(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+
+	parser := &filterParser{tokens: tokens}
+	filter, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q", tokens[parser.pos].Text)
+	}
+	return filter, nil
+}
+
+func (p *filterParser) parseOr() (methodFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(tokenOr) {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		left = func(filename string, method string) bool {
+			return a(filename, method) || b(filename, method)
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (methodFilter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(tokenAnd) {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		left = func(filename string, method string) bool {
+			return a(filename, method) && b(filename, method)
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (methodFilter, error) {
+	switch {
+	case p.peek(tokenNot):
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(filename string, method string) bool {
+			return !inner(filename, method)
+		}, nil
+
+	case p.peek(tokenOpen):
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(tokenClose) {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+
+	case p.peek(tokenWord):
+		field, ok := getUserFieldConfig(p.tokens[p.pos].Text)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", p.tokens[p.pos].Text)
+		}
+		p.pos++
+
+		if !p.peek(tokenEqual) && !p.peek(tokenNotEqual) {
+			return func(filename string, method string) bool {
+				return isFieldDone(field, getUserValue(filename, method, field.Name))
+			}, nil
+		}
+
+		negate := p.peek(tokenNotEqual)
+		p.pos++
+		value := ""
+		if p.peek(tokenWord) {
+			value = p.tokens[p.pos].Text
+			p.pos++
+		}
+		return func(filename string, method string) bool {
+			return matchFieldValue(field, getUserValue(filename, method, field.Name), value) != negate
+		}, nil
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].Text)
+	}
+	return nil, fmt.Errorf("unexpected end of filter")
+}
+
+// matchFieldValue compares a stored value with the value of a filter. Boolean
+// fields also accept true/false.
+func matchFieldValue(field UserField, stored string, value string) bool {
+	if field.Type == EnumBoolean {
+		switch strings.ToLower(value) {
+		case "1", "true", "yes":
+			return stored == "1"
+		case "0", "false", "no", "":
+			return stored != "1"
+		}
+	}
+	return stored == value
+}
+
+// getFilteredContentHtml returns the methods of the file that match the filter,
+// each one with its fields followed by its code.
+func (f fileData) getFilteredContentHtml(filter methodFilter, readOnly bool) (string, int) {
+	var content string = ""
+	var count int = 0
+	for i, method := range f.Methods {
+		if !filter(f.Filename, f.Content[method]) {
+			continue
+		}
+		count++
+
+		content += `<div id="` + getMethodAnchor(method) + `" class="mark"></div>`
+		content += getMethodFieldsHtml(f.Filename, f.Content[method], readOnly)
+		content += getCodeHtml(f.getMethodSegment(i), f.getLangHighlight())
+	}
+	return content, count
+}
+
+func reviewHandler(w http.ResponseWriter, r *http.Request) {
+	text := r.URL.Query().Get("filter")
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+	fmt.Fprint(w, "<h3>📝 Review</h3>")
+
+	fmt.Fprint(w, `<form method="get" action="/review" class="actions">`)
+	fmt.Fprint(w, `<input type="text" name="filter" value="`+parseEscapeHTML(text)+`" placeholder="!Checked || Notes"> `)
+	fmt.Fprint(w, `<button type="submit">🔎 Filter</button></form>`)
+	fmt.Fprint(w, `<p class="hint">Use <code>Field</code>, <code>!Field</code>, <code>Field=value</code>, <code>Field!=value</code>, <code>&amp;&amp;</code>, <code>||</code> and parentheses. Quote names or values with spaces.</p>`)
+
+	if text != "" {
+		filter, err := parseFilter(text)
+		if err != nil {
+			fmt.Fprint(w, `<p class="not-migrated">Invalid filter: `+parseEscapeHTML(err.Error())+`</p>`)
+		} else {
+			showReviewHtml(w, filter)
+		}
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func showReviewHtml(w http.ResponseWriter, filter methodFilter) {
+	total := 0
+	for _, filepath := range projectFiles {
+		filename := getFilename(filepath)
+		content, count := filesData[filename].getFilteredContentHtml(filter, false)
+		if count == 0 {
+			continue
+		}
+		total += count
+
+		fmt.Fprint(w, `<div class="file-section">`)
+		fmt.Fprintf(w, `<h4><a href="%s">📄 %s</a> (%d)</h4>`, parseEscapeHTML(serverPage.FileLink(filename)), parseEscapeHTML(filename), count)
+		fmt.Fprint(w, `<div class="collumns"><div class="codes">`+content+`</div></div>`)
+		fmt.Fprint(w, `</div>`)
+	}
+
+	if total == 0 {
+		fmt.Fprint(w, `<p>No methods match the filter.</p>`)
+	} else {
+		fmt.Fprintf(w, `<p>%d method(s) match the filter.</p>`, total)
+	}
+}
+
+func getReviewURL(filter string) string {
+	return "/review?filter=" + url.QueryEscape(filter)
+}
+
+// quoteFilterWord quotes a field name or value when it has spaces or operators.
+func quoteFilterWord(word string) string {
+	if word == "" || strings.ContainsAny(word, " \t&|!=()") {
+		return `"` + word + `"`
+	}
+	return word
+}
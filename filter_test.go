@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setupFilterProject() {
+	setupTestProject("/test/project/", []UserField{
+		{Name: "Checked", Type: EnumBoolean},
+		{Name: "Notes", Type: EnumTextBox},
+		{Name: "Review Status", Type: EnumChoice, Options: []string{"todo", "done"}},
+	}, testFile{"main.go", []string{"package main", "func a() {", "}", "func b() {", "}", "func c() {", "}"}})
+	setUserFields([]fieldsData{
+		{Filename: "/test/project/main.go", Method: "func a() {", Field: "Checked", Value: "1"},
+		{Filename: "/test/project/main.go", Method: "func a() {", Field: "Review Status", Value: "done"},
+		{Filename: "/test/project/main.go", Method: "func b() {", Field: "Notes", Value: "check nulls"},
+		{Filename: "/test/project/main.go", Method: "func b() {", Field: "Review Status", Value: "todo"},
+	})
+}
+
+func TestParseFilter(t *testing.T) {
+	setupFilterProject()
+
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{"Checked", []string{"a"}},
+		{"!Checked", []string{"b", "c"}},
+		{"Checked=false", []string{"b", "c"}},
+		{"Checked = true", []string{"a"}},
+		{"Notes", []string{"b"}},
+		{"!Checked && !Notes", []string{"c"}},
+		{"Checked || Notes", []string{"a", "b"}},
+		{`"Review Status"=todo`, []string{"b"}},
+		{`"Review Status"!=done`, []string{"b", "c"}},
+		{`"Review Status"=`, []string{"c"}},
+		{`!(Checked || "Review Status"=todo)`, []string{"c"}},
+		{`Checked || Notes && "Review Status"=done`, []string{"a"}},
+	}
+
+	data := filesData["main.go"]
+	for _, test := range tests {
+		filter, err := parseFilter(test.filter)
+		if err != nil {
+			t.Errorf("parseFilter(%q) failed: %v", test.filter, err)
+			continue
+		}
+		matched := make([]string, 0)
+		for _, line := range data.Methods {
+			if filter(data.Filename, data.Content[line]) {
+				matched = append(matched, extractMethodName(data.Content[line]))
+			}
+		}
+		if strings.Join(matched, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("parseFilter(%q) matched %v; expected %v", test.filter, matched, test.expected)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	setupFilterProject()
+
+	for _, filter := range []string{"", "Unknown", "Checked &&", "(Checked", "Checked)", `"Notes`, "Checked Notes", "&& Checked"} {
+		if _, err := parseFilter(filter); err == nil {
+			t.Errorf("parseFilter(%q) should fail", filter)
+		}
+	}
+}
+
+func TestReviewHandler(t *testing.T) {
+	setupFilterProject()
+
+	recorder := httptest.NewRecorder()
+	reviewHandler(recorder, httptest.NewRequest(http.MethodGet, getReviewURL("!"+quoteFilterWord("Checked")), nil))
+	body := recorder.Body.String()
+	for _, expected := range []string{"func b()", "func c()", `id="method-6"`, "2 method(s)"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Review page should contain %s", expected)
+		}
+	}
+	if strings.Contains(body, "func a()") {
+		t.Error("Review page should not contain the checked method")
+	}
+
+	if quoted := quoteFilterWord("Review Status"); quoted != `"Review Status"` {
+		t.Errorf("quoteFilterWord = %s", quoted)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const gitignoreFilename = ".gitignore"
+
+// globRule is an include, exclude or .gitignore pattern. Patterns are
+// matched against paths relative to base, with "/" as separator.
+type globRule struct {
+	Label    string
+	base     string
+	patterns [][]string // Alternativas de las llaves, separadas por segmento
+	dirOnly  bool
+	negate   bool
+}
+
+// ruleCount is what a rule excluded during the scan. The files inside the
+// excluded folders are not counted, those folders are not scanned.
+type ruleCount struct {
+	Dirs  int
+	Files int
+}
+
+type scanStats struct {
+	counts map[string]*ruleCount
+	order  []string
+}
+
+// scanFilter decides which files and folders of the project are skipped. A
+// new one is created for every folder, with the .gitignore rules of its
+// parents.
+type scanFilter struct {
+	dir       string
+	include   []globRule
+	exclude   []globRule
+	gitignore bool
+	ignored   []globRule
+	stats     *scanStats
+}
+
+// matchGlob reports whether name matches a doublestar pattern: "*" and "?"
+// match inside a folder, "**" matches any number of folders and "{a,b}"
+// matches any of the alternatives.
+func matchGlob(pattern string, name string) bool {
+	rule, err := newGlobRule(pattern, "", "")
+	return err == nil && rule.matches(name, false)
+}
+
+func newGlobRule(pattern string, base string, label string) (globRule, error) {
+	rule := globRule{Label: label, base: base}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "./"), "/")
+	if pattern == "" {
+		return rule, fmt.Errorf("empty pattern")
+	}
+
+	for _, alternative := range expandBraces(pattern) {
+		segments := strings.Split(alternative, "/")
+		for i := range segments {
+			// path.Match niega las clases con "^", .gitignore con "!"
+			segments[i] = strings.ReplaceAll(segments[i], "[!", "[^")
+			if _, err := path.Match(segments[i], ""); err != nil {
+				return rule, err
+			}
+		}
+		rule.patterns = append(rule.patterns, segments)
+	}
+	return rule, nil
+}
+
+// expandBraces returns every alternative of the first "{a,b}" group, expanding
+// the rest of the pattern recursively.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start == -1 {
+		return []string{pattern}
+	}
+	end := strings.Index(pattern[start:], "}")
+	if end == -1 {
+		return []string{pattern}
+	}
+	end += start
+
+	expanded := make([]string, 0)
+	for _, option := range strings.Split(pattern[start+1:end], ",") {
+		expanded = append(expanded, expandBraces(pattern[:start]+option+pattern[end+1:])...)
+	}
+	return expanded
+}
+
+// matches reports whether the path, relative to the project, matches the
+// rule.
+func (r globRule) matches(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(name, r.base+"/") {
+			return false
+		}
+		name = strings.TrimPrefix(name, r.base+"/")
+	}
+
+	segments := strings.Split(name, "/")
+	for _, pattern := range r.patterns {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// compileGlobRules compiles the include or exclude patterns of the config,
+// skipping the invalid ones.
+func compileGlobRules(patterns []string, kind string) []globRule {
+	rules := make([]globRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		rule, err := newGlobRule(pattern, "", fmt.Sprintf("%s %q", kind, pattern))
+		if err != nil {
+			fmt.Fprintf(logOutput, "Warning: invalid %s pattern '%s': %v\n", kind, pattern, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// parseGitignore reads the rules of a .gitignore file found in the folder
+// dir, relative to the project.
+func parseGitignore(content string, dir string) []globRule {
+	source := gitignoreFilename
+	if dir != "" {
+		source = dir + "/" + gitignoreFilename
+	}
+
+	rules := make([]globRule, 0)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negate := strings.HasPrefix(line, "!")
+		pattern := strings.TrimPrefix(line, "!")
+		pattern = strings.TrimPrefix(pattern, "\\")
+
+		// Sin barra (salvo al final) el patrón vale en cualquier nivel
+		if !strings.Contains(strings.TrimRight(pattern, "/"), "/") {
+			pattern = "**/" + pattern
+		}
+
+		rule, err := newGlobRule(pattern, dir, fmt.Sprintf("%s %q", source, line))
+		if err != nil {
+			fmt.Fprintf(logOutput, "Warning: invalid pattern '%s' in %s: %v\n", line, source, err)
+			continue
+		}
+		rule.negate = negate
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// newScanFilter prepares the filter of the root of a tree with the include
+// and exclude patterns of the config.
+func newScanFilter(root string, include []string, exclude []string, gitignore bool) *scanFilter {
+	filter := &scanFilter{
+		include:   compileGlobRules(include, "include"),
+		exclude:   compileGlobRules(exclude, "exclude"),
+		gitignore: gitignore,
+		stats:     &scanStats{counts: make(map[string]*ruleCount)},
+	}
+	filter.loadGitignore(root)
+	return filter
+}
+
+func (s *scanFilter) loadGitignore(dirPath string) {
+	if !s.gitignore {
+		return
+	}
+	content, err := os.ReadFile(filepath.Join(dirPath, gitignoreFilename))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(logOutput, "Error reading %s: %v\n", filepath.Join(dirPath, gitignoreFilename), err)
+		}
+		return
+	}
+	s.ignored = append(s.ignored[:len(s.ignored):len(s.ignored)], parseGitignore(string(content), s.dir)...)
+}
+
+func (s *scanFilter) relative(name string) string {
+	if s.dir == "" {
+		return name
+	}
+	return s.dir + "/" + name
+}
+
+// excludedBy returns the rule that excludes the path, or nil. The last
+// matching .gitignore rule wins, so "!" patterns include the path again.
+func (s *scanFilter) excludedBy(name string, isDir bool) *globRule {
+	for i := range s.exclude {
+		if s.exclude[i].matches(name, isDir) {
+			return &s.exclude[i]
+		}
+	}
+	if isDir && s.gitignore && path.Base(name) == ".git" {
+		return &globRule{Label: "the .git folder"}
+	}
+
+	var ignored *globRule
+	for i := range s.ignored {
+		if s.ignored[i].matches(name, isDir) {
+			ignored = &s.ignored[i]
+		}
+	}
+	if ignored != nil && !ignored.negate {
+		return ignored
+	}
+	return nil
+}
+
+// skipFile reports whether a file accepted by the extension filter is
+// excluded, counting it for the rule.
+func (s *scanFilter) skipFile(name string) bool {
+	if s == nil {
+		return false
+	}
+
+	name = s.relative(name)
+	if rule := s.excludedBy(name, false); rule != nil {
+		s.stats.add(rule.Label).Files++
+		return true
+	}
+
+	if len(s.include) == 0 {
+		return false
+	}
+	for _, rule := range s.include {
+		if rule.matches(name, false) {
+			return false
+		}
+	}
+	s.stats.add("include patterns (no match)").Files++
+	return true
+}
+
+// enterDir returns the filter of a subfolder, or nil and false when the
+// folder is excluded. Without filter every folder is scanned.
+func (s *scanFilter) enterDir(dirPath string, name string) (*scanFilter, bool) {
+	if s == nil {
+		return nil, true
+	}
+
+	name = s.relative(name)
+	if rule := s.excludedBy(name, true); rule != nil {
+		s.stats.add(rule.Label).Dirs++
+		return nil, false
+	}
+
+	child := *s
+	child.dir = name
+	child.loadGitignore(dirPath)
+	return &child, true
+}
+
+func (s *scanStats) add(label string) *ruleCount {
+	count, ok := s.counts[label]
+	if !ok {
+		count = &ruleCount{}
+		s.counts[label] = count
+		s.order = append(s.order, label)
+	}
+	return count
+}
+
+// logExclusions prints how many folders and files each rule excluded.
+func (s *scanFilter) logExclusions() {
+	for _, label := range s.stats.order {
+		count := s.stats.counts[label]
+		fmt.Fprintf(logOutput, "Excluded by %s: %d folder(s) skipped with their contents, %d file(s)\n", label, count.Dirs, count.Files)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "pkg/sub/main.go", true},
+		{"src/**/*.bas", "src/a/b/mod.bas", true},
+		{"src/**/*.bas", "lib/mod.bas", false},
+		{"**/vendor/**", "vendor", true},
+		{"**/vendor/**", "a/vendor/b/c.go", true},
+		{"**/vendor/**", "a/vendors/c.go", false},
+		{"bin/**", "bin/tool.go", true},
+		{"./bin/**", "bin/tool.go", true},
+		{"**/*.{bas,cls}", "forms/main.cls", true},
+		{"**/*.{bas,cls}", "forms/main.frm", false},
+		{"Form?.frm", "Form1.frm", true},
+		{"[", "[", false},
+		{"Form[!0-9].frm", "FormA.frm", true},
+		{"Form[!0-9].frm", "Form1.frm", false},
+	}
+
+	for _, test := range tests {
+		if matched := matchGlob(test.pattern, test.name); matched != test.expected {
+			t.Errorf("matchGlob(%q, %q) = %v; expected %v", test.pattern, test.name, matched, test.expected)
+		}
+	}
+}
+
+func TestParseGitignore(t *testing.T) {
+	filter := &scanFilter{gitignore: true}
+	filter.ignored = append(parseGitignore("# build output\nbin/\n*.log\n!keep.log\n/root.go\n\\#hash.go\n", ""),
+		parseGitignore("gen/*.go\n", "pkg")...)
+
+	tests := []struct {
+		name     string
+		isDir    bool
+		excluded bool
+	}{
+		{"bin", true, true},
+		{"cmd/bin", true, true},
+		{"bin", false, false},
+		{"app.log", false, true},
+		{"logs/keep.log", false, false},
+		{"root.go", false, true},
+		{"cmd/root.go", false, false},
+		{"#hash.go", false, true},
+		{"pkg/gen/types.go", false, true},
+		{"gen/types.go", false, false},
+		{"pkg/gen/sub/types.go", false, false},
+		{".git", true, true},
+	}
+
+	for _, test := range tests {
+		if excluded := filter.excludedBy(test.name, test.isDir) != nil; excluded != test.excluded {
+			t.Errorf("excludedBy(%q, %v) = %v; expected %v", test.name, test.isDir, excluded, test.excluded)
+		}
+	}
+}
+
+func TestScanProjectFiltered(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":                     "package main",
+		"debug.go":                    "package main",
+		"pkg/util.go":                 "package pkg",
+		"pkg/util_test.go":            "package pkg",
+		"pkg/.gitignore":              "*_test.go\n",
+		"vendor/lib/lib.go":           "package lib",
+		"web/node_modules/x/index.go": "package x",
+		".git/hooks/hook.go":          "package hooks",
+		".gitignore":                  "debug.go\n",
+	}
+	for name, content := range files {
+		fullPath := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configProject = config{
+		ExtFilter: []string{".go"},
+		Exclude:   []string{"vendor/", "**/node_modules/"},
+		GitIgnore: true,
+	}
+	pathProject = tmpDir + string(filepath.Separator)
+	filesData = make(map[string]fileData)
+
+	filter := newScanFilter(pathProject, configProject.Include, configProject.Exclude, configProject.GitIgnore)
+	found, err := scanTreeFiltered(pathProject, nil, isExtFilter, loadFileData, filter)
+	if err != nil {
+		t.Fatalf("scanTreeFiltered failed: %v", err)
+	}
+
+	names := make([]string, 0, len(found))
+	for _, filename := range found {
+		names = append(names, getFilename(filename))
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "main.go,pkg/util.go" {
+		t.Errorf("Unexpected files: %v", names)
+	}
+
+	expected := map[string]ruleCount{
+		`exclude "vendor/"`:          {Dirs: 1},
+		`exclude "**/node_modules/"`: {Dirs: 1},
+		"the .git folder":            {Dirs: 1},
+		`.gitignore "debug.go"`:      {Files: 1},
+		`pkg/.gitignore "*_test.go"`: {Files: 1},
+	}
+	if len(filter.stats.counts) != len(expected) {
+		t.Errorf("Unexpected rule counts: %v", filter.stats.order)
+	}
+	for label, count := range expected {
+		if got, ok := filter.stats.counts[label]; !ok || *got != count {
+			t.Errorf("count of %s = %+v; expected %+v", label, got, count)
+		}
+	}
+
+	configProject.Include = []string{"pkg/**"}
+	filter = newScanFilter(pathProject, configProject.Include, configProject.Exclude, configProject.GitIgnore)
+	found, _ = scanTreeFiltered(pathProject, nil, isExtFilter, loadFileData, filter)
+	if len(found) != 1 || filter.stats.counts["include patterns (no match)"].Files != 1 {
+		t.Errorf("Expected only pkg/util.go with the include pattern, got %v", found)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// historyEntry is a line of the history file. Files are relative to the
+// project so the history stays valid if the folder is moved.
+type historyEntry struct {
+	Time     time.Time `json:"time"`
+	Reviewer string    `json:"reviewer"`
+	File     string    `json:"file"`
+	Method   string    `json:"method"`
+	Field    string    `json:"field"`
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
+}
+
+const maxHistoryLine = 1024 * 1024
+
+var historyMutex sync.Mutex
+
+// appendHistory adds an entry at the end of the history file. The file is
+// written right away, without waiting for the save of the user fields.
+func appendHistory(entry historyEntry) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error encoding history entry: %v\n", err)
+		return
+	}
+
+	f, err := os.OpenFile(path.Join(pathProject, historyFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error opening history file: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		fmt.Fprintf(logOutput, "Error writing history file: %v\n", err)
+	}
+}
+
+// recordChange appends the change of a field to the history. filename is the
+// full path, as stored in the user fields.
+func recordChange(reviewer string, filename string, method string, field string, oldValue string, newValue string) {
+	appendHistory(historyEntry{
+		Time:     time.Now(),
+		Reviewer: reviewer,
+		File:     getFilename(filename),
+		Method:   method,
+		Field:    field,
+		Old:      oldValue,
+		New:      newValue,
+	})
+}
+
+// readHistory returns the entries of a file and method, oldest first. Empty
+// filters match everything.
+func readHistory(file string, method string) ([]historyEntry, error) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	entries := make([]historyEntry, 0)
+	f, err := os.Open(path.Join(pathProject, historyFilename))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxHistoryLine)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			fmt.Fprintf(logOutput, "Warning: invalid history entry at line %d: %v\n", line, err)
+			continue
+		}
+		if (file == "" || entry.File == file) && (method == "" || entry.Method == method) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func apiHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	entries, err := readHistory(query.Get("file"), query.Get("method"))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "error reading history: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func writeHistoryText(w io.Writer, entries []historyEntry) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Time\tReviewer\tFile\tMethod\tField\tChange")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%q → %q\n", entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.Reviewer, entry.File, entry.Method, entry.Field, entry.Old, entry.New)
+	}
+	tw.Flush()
+}
+
+func historyCommand(args []string) int {
+	var file string
+	var method string
+	var format string
+
+	flags := newCommandFlags("history")
+	flags.StringVar(&file, "file", "", "only changes of this file")
+	flags.StringVar(&method, "method", "", "only changes of this method header")
+	flags.StringVar(&format, "format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if format != "text" && format != "json" {
+		fmt.Fprintln(os.Stderr, "Invalid format:", format)
+		return 2
+	}
+	if pathProject == "" || !isValidPath(pathProject) {
+		fmt.Fprintln(os.Stderr, "Invalid project path:", pathProject)
+		return 2
+	}
+
+	entries, err := readHistory(file, method)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		return 1
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		if err := encoder.Encode(entries); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding history: %v\n", err)
+			return 1
+		}
+	} else {
+		writeHistoryText(os.Stdout, entries)
+	}
+	return 0
+}
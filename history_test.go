@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupHistoryProject(t *testing.T) {
+	setupTestProject(t.TempDir()+string(filepath.Separator), []UserField{{Name: "Checked", Type: EnumBoolean}},
+		testFile{"main.go", []string{"package main", "func a() {", "}", "func b() {", "}"}})
+}
+
+func TestChangedUserFieldHistory(t *testing.T) {
+	setupHistoryProject(t)
+
+	changedUserField(pathProject+"main.go<>func a() {<>Checked", "1", "10.0.0.1")
+	changedUserField(pathProject+"main.go<>func b() {<>Checked", "1", "10.0.0.2")
+	changedUserField(pathProject+"main.go<>func a() {<>Checked", "0", "10.0.0.2")
+
+	entries, err := readHistory("main.go", "func a() {")
+	if err != nil {
+		t.Fatalf("readHistory failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries for func a, got %d", len(entries))
+	}
+	if entries[0].Reviewer != "10.0.0.1" || entries[0].Old != "" || entries[0].New != "1" {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Reviewer != "10.0.0.2" || entries[1].Old != "1" || entries[1].New != "0" || entries[1].File != "main.go" {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+
+	if all, _ := readHistory("", ""); len(all) != 3 {
+		t.Errorf("Expected 3 entries in total, got %d", len(all))
+	}
+
+	var output bytes.Buffer
+	writeHistoryText(&output, entries)
+	if !strings.Contains(output.String(), `10.0.0.2  main.go  func a() {  Checked  "1" → "0"`) {
+		t.Errorf("Unexpected text output:\n%s", output.String())
+	}
+}
+
+func TestReadHistorySkipsInvalidLines(t *testing.T) {
+	setupHistoryProject(t)
+
+	if entries, err := readHistory("", ""); err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty history without file, got %d (%v)", len(entries), err)
+	}
+
+	content := `{"file":"main.go","method":"func a() {","field":"Checked","new":"1"}` + "\n" + `{"file":` + "\n\n"
+	if err := os.WriteFile(filepath.Join(pathProject, historyFilename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := readHistory("", ""); err != nil || len(entries) != 1 {
+		t.Errorf("Expected 1 valid entry, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestAPIHistory(t *testing.T) {
+	setupHistoryProject(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/save", strings.NewReader("name="+pathProject+"main.go<>func b() {<>Checked&value=1"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.RemoteAddr = "192.168.1.20:51234"
+	saveHandler(httptest.NewRecorder(), request)
+
+	recorder := httptest.NewRecorder()
+	apiHistoryHandler(recorder, httptest.NewRequest(http.MethodGet, apiPrefix+"/history?file=main.go&method=func%20b()%20%7B", nil))
+	var entries []historyEntry
+	if err := json.Unmarshal(recorder.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Reviewer != "192.168.1.20" {
+		t.Errorf("Unexpected history: %+v", entries)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var importColumns = []string{"file", "method", "field", "value"}
+
+// importRow is a row of the CSV matched to a method and field of the project.
+type importRow struct {
+	Line     int
+	Filename string
+	Method   string
+	Field    string
+	Value    string
+}
+
+// importError is a row of the CSV that could not be matched.
+type importError struct {
+	Line   int
+	Record []string
+	Reason string
+}
+
+type importResult struct {
+	Header    []string
+	Rows      []importRow
+	Unmatched []importError
+	Skipped   int // Filas con el valor vacío
+}
+
+// readImportCSV reads the rows of a CSV with file, method, field and value
+// columns, in any order. Excel files separated by semicolons are accepted.
+// Rows with an empty value are skipped unless clearEmpty is set, so a blank
+// cell does not wipe the stored value.
+func readImportCSV(r io.Reader, clearEmpty bool) (importResult, error) {
+	reader := bufio.NewReader(r)
+	separator := ','
+	if first, err := reader.Peek(reader.Size()); len(first) > 0 {
+		line, _, _ := bytes.Cut(first, []byte("\n"))
+		if !bytes.ContainsRune(line, ',') && bytes.ContainsRune(line, ';') {
+			separator = ';'
+		}
+	} else if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return importResult{}, err
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = separator
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return importResult{}, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return importResult{}, err
+	}
+
+	positions := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+	for _, column := range importColumns {
+		if _, ok := positions[column]; !ok {
+			return importResult{}, fmt.Errorf("missing column %q, the header must have %s", column, strings.Join(importColumns, ", "))
+		}
+	}
+
+	result := importResult{Header: header, Rows: make([]importRow, 0), Unmatched: make([]importError, 0)}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		line, _ := csvReader.FieldPos(0)
+
+		get := func(column string) string {
+			if i := positions[column]; i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		if !clearEmpty && strings.TrimSpace(get("value")) == "" {
+			result.Skipped++
+			continue
+		}
+		row, reason := matchImportRow(get("file"), get("method"), get("field"), get("value"))
+		if reason != "" {
+			result.Unmatched = append(result.Unmatched, importError{Line: line, Record: record, Reason: reason})
+			continue
+		}
+		row.Line = line
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// matchImportRow validates a row against the project files and the config. It
+// returns the reason when the row does not match.
+func matchImportRow(file string, method string, field string, value string) (importRow, string) {
+	file = strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(file), "\\", "/"), "./")
+	if file == "" || strings.TrimSpace(method) == "" || strings.TrimSpace(field) == "" {
+		return importRow{}, "missing file, method or field"
+	}
+
+	data, ok := findFileData(file)
+	if !ok {
+		return importRow{}, "file not found"
+	}
+
+	header, reason := findImportMethod(data, method)
+	if reason != "" {
+		return importRow{}, reason
+	}
+
+	fieldConfig, ok := getUserFieldConfig(strings.TrimSpace(field))
+	if !ok {
+		return importRow{}, "unknown field"
+	}
+
+	value, ok = parseImportValue(fieldConfig, value)
+	if !ok {
+		return importRow{}, "invalid value for " + string(fieldConfig.Type) + " field"
+	}
+
+	return importRow{Filename: data.Filename, Method: header, Field: fieldConfig.Name, Value: value}, ""
+}
+
+// findImportMethod finds a method by its header, ignoring the spacing, or by
+// its name when it is unique in the file.
+func findImportMethod(data fileData, method string) (string, string) {
+	normalized := normalizeHeader(method)
+	byName := make([]string, 0)
+	for _, line := range data.Methods {
+		header := data.Content[line]
+		if header == method || normalizeHeader(header) == normalized {
+			return header, ""
+		}
+		if strings.EqualFold(extractMethodName(header), normalized) {
+			byName = append(byName, header)
+		}
+	}
+
+	switch len(byName) {
+	case 0:
+		return "", "method not found"
+	case 1:
+		return byName[0], ""
+	default:
+		return "", fmt.Sprintf("method name matches %d methods", len(byName))
+	}
+}
+
+// parseImportValue converts the value of a spreadsheet to the stored value.
+// Boolean fields accept 1/0, true/false and yes/no.
+func parseImportValue(field UserField, value string) (string, bool) {
+	if field.Type == EnumBoolean {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "1", "true", "yes":
+			return "1", true
+		case "0", "false", "no", "":
+			return "0", true
+		}
+		return "", false
+	}
+	if field.Type == EnumChoice {
+		value = strings.TrimSpace(value)
+	}
+	return value, field.isValidValue(value)
+}
+
+// applyImportRows stores the values that differ from the current ones and
+// returns how many were changed. Unchecked and empty booleans are the same.
+func applyImportRows(rows []importRow, reviewer string) int {
+	changed := 0
+	for _, row := range rows {
+		fieldConfig, _ := getUserFieldConfig(row.Field)
+		if sameMergeValue(fieldConfig, getUserValue(row.Filename, row.Method, row.Field), row.Value) {
+			continue
+		}
+		if _, err := updateUserField(createFieldName(row.Filename, row.Method, row.Field), row.Value, reviewer, anyVersion); err != nil {
+			continue
+		}
+		changed++
+	}
+	return changed
+}
+
+// writeUnmatchedCSV writes the unmatched rows with the reason in a new
+// column, ready to fix and import again.
+func writeUnmatchedCSV(w io.Writer, header []string, unmatched []importError) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string{}, header...), "reason")); err != nil {
+		return err
+	}
+	for _, row := range unmatched {
+		if err := writer.Write(append(append([]string{}, row.Record...), row.Reason)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func importCommand(args []string) int {
+	var reviewer string
+	var unmatchedFile string
+	var dryRun bool
+	var clearEmpty bool
+
+	flags := newCommandFlags("import")
+	flags.StringVar(&reviewer, "user", "import", "reviewer name recorded for the imported values")
+	flags.StringVar(&unmatchedFile, "unmatched", "", "write the unmatched rows to this CSV file")
+	flags.BoolVar(&dryRun, "dry-run", false, "validate the rows without changing any value")
+	flags.BoolVar(&clearEmpty, "clear-empty", false, "clear the values of the rows with an empty value instead of skipping them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Missing the CSV file to import")
+		return 2
+	}
+
+	if !loadCommandProject() {
+		return 1
+	}
+	if !dryRun && isServerRunning() {
+		fmt.Fprintln(os.Stderr, "The server is running on this project, stop it before importing")
+		return 1
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", flags.Arg(0), err)
+		return 1
+	}
+	result, err := readImportCSV(f, clearEmpty)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", flags.Arg(0), err)
+		return 1
+	}
+
+	for _, row := range result.Unmatched {
+		fmt.Printf("Line %d: %s\t%s\n", row.Line, row.Reason, strings.Join(row.Record, "\t"))
+	}
+
+	changed := 0
+	if !dryRun {
+		changed = applyImportRows(result.Rows, cleanReviewer(reviewer))
+		saveFileUserFields()
+	}
+	fmt.Printf("%d row(s) matched, %d value(s) changed, %d row(s) unmatched\n", len(result.Rows), changed, len(result.Unmatched))
+	if result.Skipped > 0 {
+		fmt.Printf("%d row(s) with an empty value skipped (use --clear-empty to clear them)\n", result.Skipped)
+	}
+
+	if unmatchedFile != "" && len(result.Unmatched) > 0 {
+		var buf bytes.Buffer
+		err := writeUnmatchedCSV(&buf, result.Header, result.Unmatched)
+		if err == nil {
+			err = os.WriteFile(unmatchedFile, buf.Bytes(), 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", unmatchedFile, err)
+			return 1
+		}
+		fmt.Println("Unmatched rows written to " + unmatchedFile)
+	}
+
+	if len(result.Unmatched) > 0 {
+		return 1
+	}
+	return 0
+}
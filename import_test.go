@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// holdServerLock makes isServerRunning report a server on pathProject until
+// the test ends.
+func holdServerLock(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skipf("Cannot listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	if err := os.WriteFile(filepath.Join(pathProject, serverLockFilename), []byte(port+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadImportCSV(t *testing.T) {
+	setupMergeProject(t)
+	data := filesData["main.go"]
+	data.Content = append(data.Content, "func a(x int) {", "}", "func  c() {", "}")
+	data.Methods = append(data.Methods, 3, 5)
+	filesData["main.go"] = data
+
+	csv := "\ufeffFile;Method;Field;Value;Comment\n" +
+		"pkg/util.go;b;Checked;yes;by name\n" +
+		"main.go;func c() {;Notes;todo;spacing\n" +
+		"./main.go;  func a(x int) {;Status;done;header\n" +
+		"main.go;a;Checked;1;ambiguous\n" +
+		"other.go;a;Checked;1;\n" +
+		"main.go;d;Checked;1\n" +
+		"main.go;c;Owner;Ana;\n" +
+		"main.go;c;Checked;maybe;\n" +
+		"main.go;c;Status;later;\n" +
+		"main.go;;Checked;1;\n" +
+		"main.go;c;Notes;;blank\n"
+
+	result, err := readImportCSV(strings.NewReader(csv), false)
+	if err != nil {
+		t.Fatalf("readImportCSV failed: %v", err)
+	}
+
+	expected := []importRow{
+		{2, pathProject + "pkg/util.go", "func b() {", "Checked", "1"},
+		{3, pathProject + "main.go", "func  c() {", "Notes", "todo"},
+		{4, pathProject + "main.go", "func a(x int) {", "Status", "done"},
+	}
+	if len(result.Rows) != len(expected) {
+		t.Fatalf("Expected %d matched rows, got %+v", len(expected), result.Rows)
+	}
+	for i, row := range result.Rows {
+		if row != expected[i] {
+			t.Errorf("row %d = %+v; expected %+v", i, row, expected[i])
+		}
+	}
+
+	reasons := []string{"method name matches 2 methods", "file not found", "method not found", "unknown field",
+		"invalid value for boolean field", "invalid value for choice field", "missing file, method or field"}
+	if len(result.Unmatched) != len(reasons) {
+		t.Fatalf("Expected %d unmatched rows, got %+v", len(reasons), result.Unmatched)
+	}
+	for i, row := range result.Unmatched {
+		if row.Reason != reasons[i] || row.Line != i+5 {
+			t.Errorf("unmatched %d = line %d %q; expected line %d %q", i, row.Line, row.Reason, i+5, reasons[i])
+		}
+	}
+
+	if result.Skipped != 1 {
+		t.Errorf("Expected 1 skipped row with an empty value, got %d", result.Skipped)
+	}
+
+	cleared, err := readImportCSV(strings.NewReader("file,method,field,value\nmain.go,c,Notes,\nmain.go,c,Checked,\n"), true)
+	if err != nil || len(cleared.Rows) != 2 || cleared.Rows[0].Value != "" || cleared.Rows[1].Value != "0" {
+		t.Errorf("Empty values should be imported with clearEmpty, got %+v %v", cleared.Rows, err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeUnmatchedCSV(&buf, result.Header, result.Unmatched[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "Comment,reason\nmain.go,a,Checked,1,ambiguous,method name matches 2 methods\n") {
+		t.Errorf("Unexpected unmatched CSV:\n%s", buf.String())
+	}
+
+	if _, err := readImportCSV(strings.NewReader("file,method,value\n"), false); err == nil {
+		t.Error("readImportCSV should fail without the field column")
+	}
+}
+
+func TestApplyImportRows(t *testing.T) {
+	setupMergeProject(t)
+	setUserFields([]fieldsData{{Filename: pathProject + "main.go", Method: "func a() {", Field: "Notes", Value: "same"}})
+
+	rows := []importRow{
+		{2, pathProject + "main.go", "func a() {", "Checked", "1"},
+		{3, pathProject + "main.go", "func a() {", "Notes", "same"},
+		{4, pathProject + "pkg/util.go", "func b() {", "Checked", "0"},
+	}
+	if changed := applyImportRows(rows, "sheet"); changed != 1 {
+		t.Errorf("Expected 1 changed value, got %d", changed)
+	}
+
+	userField, _ := getUserField(pathProject+"main.go", "func a() {", "Checked")
+	if userField.Value != "1" || userField.Reviewer != "sheet" {
+		t.Errorf("Unexpected imported value: %+v", userField)
+	}
+	if entries, _ := readHistory("", ""); len(entries) != 1 {
+		t.Errorf("Expected 1 history entry, got %d", len(entries))
+	}
+}
+
+func TestImportCommandWithServerRunning(t *testing.T) {
+	dir := setupStatsProject(t)
+	pathProject = dir
+	holdServerLock(t)
+
+	csvFile := filepath.Join(t.TempDir(), "values.csv")
+	if err := os.WriteFile(csvFile, []byte("file,method,field,value\nmain.go,d,Notes,imported\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(dir + userFieldsFilename)
+
+	if code := importCommand([]string{"--path", dir, csvFile}); code != 1 {
+		t.Errorf("Import with the server running returned %d; expected 1", code)
+	}
+	if after, _ := os.ReadFile(dir + userFieldsFilename); !bytes.Equal(before, after) {
+		t.Error("Import with the server running should not change the user fields")
+	}
+	if isValidFile(dir + historyFilename) {
+		t.Error("Import with the server running should not record history")
+	}
+
+	if code := importCommand([]string{"--path", dir, "--dry-run", csvFile}); code != 0 {
+		t.Errorf("Dry run with the server running returned %d; expected 0", code)
+	}
+}
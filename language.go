@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	encodingAuto        = "auto"
+	encodingUTF8        = "utf-8"
+	encodingWindows1252 = "windows-1252"
+)
+
+// langProfile overrides the project settings for the files of an extension.
+// Empty settings use the ones of the project.
+type langProfile struct {
+	LangHighlight string   `json:"lang_highlight,omitempty"`
+	MethodFilter  []string `json:"method_filter,omitempty"`
+	Encoding      string   `json:"encoding,omitempty"`      // auto, utf-8 o windows-1252
+	LineComment   string   `json:"line_comment,omitempty"`  // Como "'" o "--"
+	BlockComment  []string `json:"block_comment,omitempty"` // Inicio y fin, como ["/*", "*/"]
+}
+
+// fileProfile is the compiled profile used to load and show a file.
+type fileProfile struct {
+	LangHighlight string
+	MethodRegexes []*regexp.Regexp
+	Encoding      string
+	LineComment   string
+	BlockStart    string
+	BlockEnd      string
+}
+
+var fileProfiles map[string]fileProfile
+
+// getDefaultFileProfile returns the project settings, used by the files
+// without a profile.
+func getDefaultFileProfile() fileProfile {
+	return fileProfile{
+		LangHighlight: configProject.LangHighlight,
+		MethodRegexes: methodFilterRegexes,
+		Encoding:      encodingAuto,
+	}
+}
+
+// compileLangProfiles prepares the profiles of the config.
+func compileLangProfiles() {
+	fileProfiles = make(map[string]fileProfile)
+	for ext, lang := range configProject.Languages {
+		profile := getDefaultFileProfile()
+		if lang.LangHighlight != "" {
+			profile.LangHighlight = lang.LangHighlight
+		}
+		if len(lang.MethodFilter) > 0 {
+			profile.MethodRegexes = compileMethodFilter(lang.MethodFilter)
+		}
+
+		switch encoding := strings.ToLower(lang.Encoding); encoding {
+		case "", encodingAuto:
+		case encodingUTF8, "utf8":
+			profile.Encoding = encodingUTF8
+		case encodingWindows1252, "cp1252", "ansi":
+			profile.Encoding = encodingWindows1252
+		default:
+			fmt.Fprintf(logOutput, "Warning: unknown encoding '%s' for %s, detecting it\n", lang.Encoding, ext)
+		}
+
+		profile.LineComment = lang.LineComment
+		if len(lang.BlockComment) == 2 && lang.BlockComment[0] != "" && lang.BlockComment[1] != "" {
+			profile.BlockStart, profile.BlockEnd = lang.BlockComment[0], lang.BlockComment[1]
+		} else if len(lang.BlockComment) > 0 {
+			fmt.Fprintf(logOutput, "Warning: block_comment for %s needs a start and an end\n", ext)
+		}
+
+		fileProfiles[ext] = profile
+	}
+}
+
+// getFileProfile returns the profile of the file extension, or the project
+// settings when it has none.
+func getFileProfile(filename string) fileProfile {
+	if profile, ok := fileProfiles[filepath.Ext(filename)]; ok {
+		return profile
+	}
+	return getDefaultFileProfile()
+}
+
+func (f fileData) getLangHighlight() string {
+	return getFileProfile(f.Filename).LangHighlight
+}
+
+// decode converts the content of a file to UTF-8. Files not valid as UTF-8
+// are read as Windows-1252 when the encoding is detected.
+func (p fileProfile) decode(data []byte) string {
+	switch p.Encoding {
+	case encodingUTF8:
+		return string(data)
+	case encodingWindows1252:
+		return fromWindows1252(string(data))
+	}
+
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return fromWindows1252(string(data))
+}
+
+// stripComments removes the comments of a line so commented out methods are
+// not found. inBlock tells whether the line starts inside a block comment and
+// the returned value whether the next one does. Comment marks inside "..." or
+// '...' strings are kept; "\" escapes a quote unless "'" starts a comment, as
+// in VB where the backslash is a normal character.
+func (p fileProfile) stripComments(line string, inBlock bool) (string, bool) {
+	if p.LineComment == "" && p.BlockStart == "" {
+		return line, false
+	}
+
+	var code strings.Builder
+	for i := 0; i < len(line); {
+		if inBlock {
+			end := strings.Index(line[i:], p.BlockEnd)
+			if end == -1 {
+				return code.String(), true
+			}
+			i += end + len(p.BlockEnd)
+			inBlock = false
+			continue
+		}
+
+		rest := line[i:]
+		if p.LineComment != "" && strings.HasPrefix(rest, p.LineComment) {
+			break
+		}
+		if p.BlockStart != "" && strings.HasPrefix(rest, p.BlockStart) {
+			i += len(p.BlockStart)
+			inBlock = true
+			continue
+		}
+
+		if quote := line[i]; quote == '"' || (quote == '\'' && p.LineComment != "'") {
+			end := p.stringEnd(line, i)
+			code.WriteString(line[i:end])
+			i = end
+			continue
+		}
+
+		code.WriteByte(line[i])
+		i++
+	}
+	return code.String(), inBlock
+}
+
+// stringEnd returns the position after the string that starts at start, or
+// the end of the line when it is not closed.
+func (p fileProfile) stringEnd(line string, start int) int {
+	quote := line[start]
+	for i := start + 1; i < len(line); i++ {
+		if line[i] == '\\' && p.LineComment != "'" {
+			i++ // Carácter escapado
+			continue
+		}
+		if line[i] == quote {
+			return i + 1
+		}
+	}
+	return len(line)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupLanguageProject() {
+	configProject = config{
+		LangHighlight: "vb",
+		ExtFilter:     []string{".bas"},
+		MethodFilter:  []string{"Sub (.*)"},
+		Languages: map[string]langProfile{
+			".sql": {
+				LangHighlight: "sql",
+				MethodFilter:  []string{`(?i)create\s+procedure`},
+				LineComment:   "--",
+				BlockComment:  []string{"/*", "*/"},
+			},
+			".frm": {Encoding: "windows-1252", LineComment: "'"},
+		},
+	}
+	methodFilterRegexes = compileMethodFilter(configProject.MethodFilter)
+	compileLangProfiles()
+}
+
+func TestGetFileProfile(t *testing.T) {
+	setupLanguageProject()
+
+	tests := []struct {
+		filename string
+		lang     string
+		encoding string
+		pattern  string
+	}{
+		{"/p/module.bas", "vb", encodingAuto, "Sub (.*)"},
+		{"/p/schema.sql", "sql", encodingAuto, `(?i)create\s+procedure`},
+		{"/p/main.frm", "vb", encodingWindows1252, "Sub (.*)"},
+	}
+
+	for _, test := range tests {
+		profile := getFileProfile(test.filename)
+		if profile.LangHighlight != test.lang || profile.Encoding != test.encoding || profile.MethodRegexes[0].String() != test.pattern {
+			t.Errorf("getFileProfile(%s) = %+v", test.filename, profile)
+		}
+	}
+
+	if !isExtFilter("/p/schema.sql") || isExtFilter("/p/app.js") {
+		t.Error("Extensions with a profile should be loaded")
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	profile := fileProfile{LineComment: "--", BlockStart: "/*", BlockEnd: "*/"}
+
+	tests := []struct {
+		line     string
+		inBlock  bool
+		code     string
+		endBlock bool
+	}{
+		{"CREATE PROCEDURE a", false, "CREATE PROCEDURE a", false},
+		{"-- CREATE PROCEDURE a", false, "", false},
+		{"SELECT 1 -- note", false, "SELECT 1 ", false},
+		{"SELECT /* x */ 1", false, "SELECT  1", false},
+		{"SELECT 1 /* start", false, "SELECT 1 ", true},
+		{"CREATE PROCEDURE old", true, "", true},
+		{"", true, "", true},
+		{"end */ SELECT 2 -- x", true, " SELECT 2 ", false},
+		{"/* -- */ CREATE PROCEDURE b", false, " CREATE PROCEDURE b", false},
+		{"SELECT '/*' AS a", false, "SELECT '/*' AS a", false},
+		{"SELECT 'it''s -- x' -- y", false, "SELECT 'it''s -- x' ", false},
+		{"SELECT \"--\" /* z */", false, "SELECT \"--\" ", false},
+	}
+
+	for _, test := range tests {
+		code, inBlock := profile.stripComments(test.line, test.inBlock)
+		if code != test.code || inBlock != test.endBlock {
+			t.Errorf("stripComments(%q, %v) = %q, %v; expected %q, %v", test.line, test.inBlock, code, inBlock, test.code, test.endBlock)
+		}
+	}
+
+	// Las comillas escapadas y las de VB, donde ' es un comentario
+	quoted := []struct {
+		profile fileProfile
+		line    string
+		code    string
+	}{
+		{fileProfile{LineComment: "//", BlockStart: "/*", BlockEnd: "*/"}, `var s = "a\"/*"; // c`, `var s = "a\"/*"; `},
+		{fileProfile{LineComment: "//", BlockStart: "/*", BlockEnd: "*/"}, `var s = 'open /*`, `var s = 'open /*`},
+		{fileProfile{LineComment: "'"}, `MsgBox "it's" ' note`, `MsgBox "it's" `},
+		{fileProfile{LineComment: "'"}, `Path = "C:\" ' folder`, `Path = "C:\" `},
+	}
+	for _, test := range quoted {
+		if code, _ := test.profile.stripComments(test.line, false); code != test.code {
+			t.Errorf("stripComments(%q) = %q; expected %q", test.line, code, test.code)
+		}
+	}
+
+	if code, inBlock := (fileProfile{}).stripComments("' Sub a()", true); code != "' Sub a()" || inBlock {
+		t.Error("Profiles without comment syntax should not change the line")
+	}
+}
+
+func TestReadFileDataWithProfile(t *testing.T) {
+	setupLanguageProject()
+	dir := t.TempDir()
+
+	sql := "/* CREATE PROCEDURE disabled\nCREATE PROCEDURE old */\n-- CREATE PROCEDURE commented\nCREATE PROCEDURE active\nAS SELECT 1"
+	sqlFile := filepath.Join(dir, "schema.sql")
+	if err := os.WriteFile(sqlFile, []byte(sql), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readFileData(sqlFile, getFileProfile(sqlFile))
+	if err != nil {
+		t.Fatalf("readFileData failed: %v", err)
+	}
+	if len(data.Methods) != 1 || data.Content[data.Methods[0]] != "CREATE PROCEDURE active" {
+		t.Errorf("Expected only the active procedure, got %v", data.Methods)
+	}
+
+	// El perfil de .frm lee el archivo como Windows-1252
+	frmFile := filepath.Join(dir, "main.frm")
+	if err := os.WriteFile(frmFile, []byte("' Sub Hidden()\nSub Caf\xe9()\nEnd Sub"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err = readFileData(frmFile, getFileProfile(frmFile))
+	if err != nil {
+		t.Fatalf("readFileData failed: %v", err)
+	}
+	if len(data.Methods) != 1 || data.Content[1] != "Sub Café()" {
+		t.Errorf("Unexpected form data: %q %v", data.Content, data.Methods)
+	}
+
+	data.Filename = sqlFile
+	if html := data.getContentHtml(true); !strings.Contains(html, `<code class="sql">`) {
+		t.Errorf("Expected sql highlighting, got %s", html)
+	}
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 )
 
 const (
@@ -13,26 +16,50 @@ const (
 var (
 	pathProject string
 	listenPort  = "80"
+
+	// logOutput receives the banner, the loading messages and the errors.
+	// Commands send them to stderr, leaving stdout for their result.
+	logOutput io.Writer = os.Stdout
 )
 
 func main() {
-	fmt.Println("Zoomer Project v" + Version + " by ^[GS]^")
+	banner := "Zoomer Project v" + Version + " by ^[GS]^"
+
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		logOutput = os.Stderr
+		fmt.Fprintln(logOutput, banner)
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	fmt.Fprintln(logOutput, banner)
 
 	flag.StringVar(&pathProject, "path", "", "project path")
 	flag.StringVar(&listenPort, "port", "80", "port to listen")
+	flag.StringVar(&pathTarget, "target", "", "migrated project path")
+	flag.StringVar(&defaultReviewer, "user", "", "reviewer name for every change (single-user mode)")
 	flag.Parse()
+	defaultReviewer = cleanReviewer(defaultReviewer)
 
-	if pathProject == "" || !isValidPath(pathProject) || !isValidPort(listenPort) {
-		fmt.Println("Usage: zoomer --path <project path> [--port <port>]")
+	if pathProject == "" || !isValidPath(pathProject) || !isValidPort(listenPort) ||
+		(pathTarget != "" && !isValidPath(pathTarget)) {
+		printUsage()
 		return
 	}
 
 	if !loadProject() {
-		fmt.Println("Error loading project")
+		fmt.Fprintln(logOutput, "Error loading project")
+		return
+	}
+
+	if !loadTarget() || !loadMappings() {
+		fmt.Fprintln(logOutput, "Error loading target")
 		return
 	}
 
 	go waitToSave()
 
 	initServer()
+
+	// Guardar los cambios que el guardado periódico aún no escribió
+	saveFileUserFields()
 }
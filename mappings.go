@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// methodLink records that a legacy method was migrated to a target method.
+// Filenames are relative to --path and --target respectively.
+type methodLink struct {
+	LegacyFile   string
+	LegacyMethod string
+	TargetFile   string
+	TargetMethod string
+}
+
+var (
+	methodLinks      []methodLink
+	methodLinksMutex sync.Mutex
+
+	// Prefijos de controles VB6 que no forman parte del nombre migrado
+	legacyNamePrefixes = map[string]bool{
+		"cmd": true, "frm": true, "btn": true, "txt": true, "lbl": true,
+		"mnu": true, "cbo": true, "chk": true, "opt": true, "lst": true,
+		"tmr": true, "pic": true, "img": true, "fra": true, "grd": true,
+		"dlg": true, "mod": true, "cls": true,
+	}
+)
+
+// splitMethodName splits a method name in words, supporting PascalCase,
+// camelCase and snake_case: "cmdLoad_Customers" -> [cmd Load Customers].
+func splitMethodName(name string) []string {
+	words := make([]string, 0)
+	word := make([]rune, 0)
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// normalizeMethodName converts a method name to a comparable form across
+// naming conventions: "cmdLoad_Customers", "LoadCustomers" and
+// "load_customers" all become "loadcustomers".
+func normalizeMethodName(name string) string {
+	words := splitMethodName(name)
+	if len(words) > 1 && legacyNamePrefixes[strings.ToLower(words[0])] {
+		words = words[1:]
+	}
+	return strings.ToLower(strings.Join(words, ""))
+}
+
+func loadMappings() bool {
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	methodLinks = make([]methodLink, 0)
+
+	mappingsPath := path.Join(pathProject, mappingsFilename)
+	if !isValidFile(mappingsPath) {
+		return true
+	}
+
+	mappingsFile, err := os.Open(mappingsPath)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error opening mappings file: %v\n", err)
+		return false
+	}
+	defer mappingsFile.Close()
+
+	decoder := json.NewDecoder(mappingsFile)
+	err = decoder.Decode(&methodLinks)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error decoding mappings file: %v\n", err)
+		return false
+	}
+
+	fmt.Fprintf(logOutput, "Mappings loaded: %d link(s)\n", len(methodLinks))
+	return true
+}
+
+// saveMappings writes the links right away, they change rarely.
+func saveMappings() bool {
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	data, err := json.MarshalIndent(methodLinks, "", "    ")
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error encoding mappings: %v\n", err)
+		return false
+	}
+
+	if err := writeFileAtomic(path.Join(pathProject, mappingsFilename), append(data, '\n')); err != nil {
+		fmt.Fprintf(logOutput, "Error saving mappings file: %v\n", err)
+		return false
+	}
+
+	return true
+}
+
+func addMethodLink(link methodLink) error {
+	legacy, ok := filesData[link.LegacyFile]
+	if !ok || legacy.getMethodHash(link.LegacyMethod) == "" {
+		return fmt.Errorf("legacy method not found: %s %s", link.LegacyFile, link.LegacyMethod)
+	}
+	if getTargetMethodSegment(methodRef{link.TargetFile, link.TargetMethod}) == nil {
+		return fmt.Errorf("target method not found: %s %s", link.TargetFile, link.TargetMethod)
+	}
+
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	for _, existing := range methodLinks {
+		if existing == link {
+			return nil
+		}
+	}
+	methodLinks = append(methodLinks, link)
+	return nil
+}
+
+func removeMethodLink(link methodLink) bool {
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	for i, existing := range methodLinks {
+		if existing == link {
+			methodLinks = append(methodLinks[:i], methodLinks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// renameLinkedMethod points the links of a legacy method to its new header.
+// Returns the number of links changed.
+func renameLinkedMethod(legacyFile string, oldMethod string, newMethod string) int {
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	changed := 0
+	renamed := make([]methodLink, 0, len(methodLinks))
+	for _, link := range methodLinks {
+		if link.LegacyFile == legacyFile && link.LegacyMethod == oldMethod {
+			link.LegacyMethod = newMethod
+			changed++
+		}
+		if !containsMethodLink(renamed, link) {
+			renamed = append(renamed, link)
+		}
+	}
+	methodLinks = renamed
+	return changed
+}
+
+func containsMethodLink(links []methodLink, link methodLink) bool {
+	for _, existing := range links {
+		if existing == link {
+			return true
+		}
+	}
+	return false
+}
+
+func getMethodLinks() []methodLink {
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	return append([]methodLink{}, methodLinks...)
+}
+
+// getLinkedMethods returns the target methods explicitly linked to a legacy
+// method.
+func getLinkedMethods(legacyFile string, legacyMethod string) []methodRef {
+	methodLinksMutex.Lock()
+	defer methodLinksMutex.Unlock()
+
+	refs := make([]methodRef, 0)
+	for _, link := range methodLinks {
+		if link.LegacyFile == legacyFile && link.LegacyMethod == legacyMethod {
+			refs = append(refs, methodRef{link.TargetFile, link.TargetMethod})
+		}
+	}
+	return refs
+}
+
+// getSuggestedMethods returns the target methods whose normalized name
+// matches the legacy method.
+func getSuggestedMethods(legacyMethod string) []methodRef {
+	refs := append([]methodRef{}, targetMethodsByName[normalizeMethodName(extractMethodName(legacyMethod))]...)
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Filename < refs[j].Filename
+	})
+	return refs
+}
+
+// getUnmappedMethods lists the legacy methods without explicit links.
+func getUnmappedMethods() []methodRef {
+	linked := make(map[methodRef]bool)
+	for _, link := range getMethodLinks() {
+		linked[methodRef{link.LegacyFile, link.LegacyMethod}] = true
+	}
+
+	unmapped := make([]methodRef, 0)
+	for _, filepath := range projectFiles {
+		filename := getFilename(filepath)
+		data := filesData[filename]
+		for _, line := range data.Methods {
+			ref := methodRef{filename, data.Content[line]}
+			if !linked[ref] {
+				unmapped = append(unmapped, ref)
+			}
+		}
+	}
+	return unmapped
+}
+
+// acceptSuggestedLinks links every unmapped legacy method with exactly one
+// suggestion. Returns the number of links added.
+func acceptSuggestedLinks() int {
+	added := 0
+	for _, ref := range getUnmappedMethods() {
+		suggested := getSuggestedMethods(ref.Method)
+		if len(suggested) != 1 {
+			continue // Ambiguo o sin sugerencias
+		}
+		if addMethodLink(methodLink{ref.Filename, ref.Method, suggested[0].Filename, suggested[0].Method}) == nil {
+			added++
+		}
+	}
+	return added
+}
+
+func mappingsCommand(args []string) int {
+	var accept bool
+
+	flags := newCommandFlags("mappings")
+	flags.StringVar(&pathTarget, "target", "", "migrated project path")
+	flags.BoolVar(&accept, "accept", false, "link the methods with a single suggestion")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if pathTarget == "" || !isValidPath(pathTarget) {
+		fmt.Fprintln(os.Stderr, "Invalid target path:", pathTarget)
+		return 2
+	}
+
+	if !loadCommandProject() || !loadTarget() || !loadMappings() {
+		return 1
+	}
+
+	if accept {
+		fmt.Printf("Accepted %d suggested link(s)\n", acceptSuggestedLinks())
+		if !saveMappings() {
+			return 1
+		}
+	}
+
+	unmapped := getUnmappedMethods()
+	for _, ref := range unmapped {
+		suggestions := make([]string, 0)
+		for _, suggested := range getSuggestedMethods(ref.Method) {
+			suggestions = append(suggestions, suggested.Filename+" "+suggested.Method)
+		}
+		fmt.Printf("%s\t%s\t%s\n", ref.Filename, ref.Method, strings.Join(suggestions, "; "))
+	}
+	fmt.Printf("%d unmapped method(s)\n", len(unmapped))
+	return 0
+}
+
+func mappingsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		targetFile, targetMethod, _ := strings.Cut(r.Form.Get("target"), `<>`)
+		link := methodLink{r.Form.Get("legacy_file"), r.Form.Get("legacy_method"), targetFile, targetMethod}
+
+		switch r.Form.Get("action") {
+		case "link":
+			if err := addMethodLink(link); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, parseEscapeHTML(err.Error()))
+				return
+			}
+		case "unlink":
+			removeMethodLink(link)
+		case "accept":
+			acceptSuggestedLinks()
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !saveMappings() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		redirect := r.Form.Get("return")
+		if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+			redirect = "/mappings"
+		}
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+
+	fmt.Fprint(w, "<h3>🔗 Method links</h3>")
+	fmt.Fprint(w, `<div class="file-section"><table class="report">`)
+	fmt.Fprint(w, `<tr><th>Legacy file</th><th>Legacy method</th><th>Target file</th><th>Target method</th><th></th></tr>`)
+	for _, link := range getMethodLinks() {
+		fmt.Fprint(w, `<tr><td>`+parseEscapeHTML(link.LegacyFile)+`</td><td><code>`+parseEscapeHTML(link.LegacyMethod)+`</code></td>`)
+		fmt.Fprint(w, `<td>`+parseEscapeHTML(link.TargetFile)+`</td><td><code>`+parseEscapeHTML(link.TargetMethod)+`</code></td><td>`)
+		fmt.Fprint(w, getLinkFormHtml("unlink", "✖️ Unlink", link.LegacyFile, link.LegacyMethod, methodRef{link.TargetFile, link.TargetMethod}, "/mappings"))
+		fmt.Fprint(w, `</td></tr>`)
+	}
+	fmt.Fprint(w, `</table></div>`)
+
+	unmapped := getUnmappedMethods()
+	fmt.Fprintf(w, "<h3>⏳ Unmapped methods (%d)</h3>", len(unmapped))
+	fmt.Fprint(w, `<form method="post" action="/mappings" class="actions"><button type="submit" name="action" value="accept">✔️ Accept all unambiguous suggestions</button></form><br>`)
+	fmt.Fprint(w, `<div class="file-section"><table class="report">`)
+	fmt.Fprint(w, `<tr><th>Legacy file</th><th>Legacy method</th><th>Suggestions</th></tr>`)
+	for _, ref := range unmapped {
+		fmt.Fprint(w, `<tr><td><a href="/migration?file=`+url.QueryEscape(ref.Filename)+`">`+parseEscapeHTML(ref.Filename)+`</a></td>`)
+		fmt.Fprint(w, `<td><code>`+parseEscapeHTML(ref.Method)+`</code></td><td>`)
+		for _, suggested := range getSuggestedMethods(ref.Method) {
+			fmt.Fprint(w, getLinkFormHtml("link", "🔗 "+suggested.Filename, ref.Filename, ref.Method, suggested, "/mappings"))
+		}
+		fmt.Fprint(w, `</td></tr>`)
+	}
+	fmt.Fprint(w, `</table></div>`)
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func getLinkFormHtml(action string, label string, legacyFile string, legacyMethod string, target methodRef, redirect string) string {
+	var html string = `<form method="post" action="/mappings" class="actions inline">`
+	html += `<input type="hidden" name="legacy_file" value="` + parseEscapeHTML(legacyFile) + `">`
+	html += `<input type="hidden" name="legacy_method" value="` + parseEscapeHTML(legacyMethod) + `">`
+	html += `<input type="hidden" name="target" value="` + parseEscapeHTML(target.Filename+`<>`+target.Method) + `">`
+	html += `<input type="hidden" name="return" value="` + parseEscapeHTML(redirect) + `">`
+	html += `<button type="submit" name="action" value="` + action + `">` + parseEscapeHTML(label) + `</button>`
+	html += `</form>`
+	return html
+}
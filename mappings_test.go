@@ -0,0 +1,130 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNormalizeMethodName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"LoadCustomers", "loadcustomers"},
+		{"loadCustomers", "loadcustomers"},
+		{"load_customers", "loadcustomers"},
+		{"cmdLoad_Customers", "loadcustomers"},
+		{"cmdSave_Click", "saveclick"},
+		{"frmMain", "main"},
+		{"cmd", "cmd"}, // Only a prefix, nothing to strip
+		{"command", "command"},
+		{"HTTPServer", "httpserver"},
+	}
+
+	for _, test := range tests {
+		result := normalizeMethodName(test.input)
+		if result != test.expected {
+			t.Errorf("normalizeMethodName(%s) = %s; expected %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestSplitMethodName(t *testing.T) {
+	words := splitMethodName("cmdLoadHTTPServer_Click")
+	expected := []string{"cmd", "Load", "HTTP", "Server", "Click"}
+	if len(words) != len(expected) {
+		t.Fatalf("splitMethodName() = %v; expected %v", words, expected)
+	}
+	for i := range words {
+		if words[i] != expected[i] {
+			t.Errorf("splitMethodName() = %v; expected %v", words, expected)
+		}
+	}
+}
+
+func setupMappingsProject() {
+	pathProject = "/test/project/"
+	pathTarget = "/test/target/"
+	projectFiles = []string{"/test/project/Form1.frm"}
+	filesData = map[string]fileData{
+		"Form1.frm": {
+			Filename: "/test/project/Form1.frm",
+			Content:  []string{"Private Sub cmdLoadCustomers_Click()", "End Sub", "Private Sub Unported()", "End Sub"},
+			Methods:  []int{0, 2},
+		},
+	}
+	targetFilesData = map[string]fileData{
+		"customers.go": {
+			Filename: "/test/target/customers.go",
+			Content:  []string{"package main", "func LoadCustomersClick() {", "}"},
+			Methods:  []int{1},
+		},
+	}
+	targetMethodsByName = map[string][]methodRef{
+		"loadcustomersclick": {{"customers.go", "func LoadCustomersClick() {"}},
+	}
+	methodLinks = make([]methodLink, 0)
+}
+
+func TestMethodLinks(t *testing.T) {
+	setupMappingsProject()
+
+	suggested := getSuggestedMethods("Private Sub cmdLoadCustomers_Click()")
+	if len(suggested) != 1 || suggested[0].Filename != "customers.go" {
+		t.Fatalf("Unexpected suggestions: %+v", suggested)
+	}
+
+	if len(getUnmappedMethods()) != 2 {
+		t.Errorf("Expected 2 unmapped methods, got %d", len(getUnmappedMethods()))
+	}
+
+	// Links to unknown methods are rejected
+	if err := addMethodLink(methodLink{"Form1.frm", "Private Sub Missing()", "customers.go", "func LoadCustomersClick() {"}); err == nil {
+		t.Error("Expected error linking a missing legacy method")
+	}
+	if err := addMethodLink(methodLink{"Form1.frm", "Private Sub Unported()", "customers.go", "func Missing() {"}); err == nil {
+		t.Error("Expected error linking a missing target method")
+	}
+
+	if added := acceptSuggestedLinks(); added != 1 {
+		t.Errorf("Expected 1 accepted suggestion, got %d", added)
+	}
+
+	refs, isSuggestion := getMigratedMethods("Form1.frm", "Private Sub cmdLoadCustomers_Click()")
+	if isSuggestion || len(refs) != 1 {
+		t.Errorf("Expected an explicit link, got %+v (suggested: %v)", refs, isSuggestion)
+	}
+
+	unmapped := getUnmappedMethods()
+	if len(unmapped) != 1 || unmapped[0].Method != "Private Sub Unported()" {
+		t.Errorf("Unexpected unmapped methods: %+v", unmapped)
+	}
+
+	link := methodLink{"Form1.frm", "Private Sub cmdLoadCustomers_Click()", "customers.go", "func LoadCustomersClick() {"}
+	if !removeMethodLink(link) {
+		t.Error("Expected link to be removed")
+	}
+	if len(getMethodLinks()) != 0 {
+		t.Errorf("Expected no links, got %d", len(getMethodLinks()))
+	}
+
+	pathTarget = ""
+}
+
+func TestSaveLoadMappings(t *testing.T) {
+	pathProject = t.TempDir()
+	methodLinks = []methodLink{
+		{"Form1.frm", "Private Sub Load()", "load.go", "func Load() {"},
+	}
+
+	if !saveMappings() {
+		t.Fatal("saveMappings() failed")
+	}
+
+	methodLinks = nil
+	if !loadMappings() {
+		t.Fatal("loadMappings() failed")
+	}
+	if len(methodLinks) != 1 || methodLinks[0].TargetMethod != "func Load() {" {
+		t.Errorf("Unexpected loaded links: %+v", methodLinks)
+	}
+}
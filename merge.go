@@ -0,0 +1,529 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mergeSource is a user fields file to merge. The first source is the
+// project itself and wins unresolved conflicts.
+type mergeSource struct {
+	Name   string
+	Fields []fieldsData
+}
+
+// mergeCandidate is one of the values found for a conflicting field.
+type mergeCandidate struct {
+	Source    string     `json:"source"`
+	Value     string     `json:"value"`
+	Reviewer  string     `json:"reviewer,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// mergeConflict is a field with different values in the merged files. The
+// file is relative to the project.
+type mergeConflict struct {
+	File       string           `json:"file"`
+	Method     string           `json:"method"`
+	Field      string           `json:"field"`
+	Candidates []mergeCandidate `json:"candidates"`
+}
+
+type mergeResult struct {
+	Fields    []fieldsData
+	Conflicts []mergeConflict
+	Resolved  []fieldsData
+}
+
+type sourcedField struct {
+	source string
+	field  fieldsData
+}
+
+var mergePolicies = []string{"newest", "or", "concat"}
+
+var (
+	mergeConflicts      []mergeConflict
+	mergeConflictsMutex sync.Mutex
+)
+
+// parseMergePolicies reads a comma separated list of policies. They are
+// tried in order until one applies to the field.
+func parseMergePolicies(value string) ([]string, error) {
+	policies := make([]string, 0)
+	for _, policy := range strings.Split(value, ",") {
+		policy = strings.TrimSpace(policy)
+		if policy == "" {
+			continue
+		}
+		if !containsString(mergePolicies, policy) {
+			return nil, fmt.Errorf("unknown merge policy %q, expected %s", policy, strings.Join(mergePolicies, ", "))
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// mergeUserFields combines the values of every source. Equal values are
+// merged silently, different values are solved with the policies or kept
+// as conflicts with the value of the first source that has the field.
+func mergeUserFields(sources []mergeSource, policies []string) mergeResult {
+	type fieldKey struct {
+		filename, method, field string
+	}
+
+	order := make([]fieldKey, 0)
+	found := make(map[fieldKey][]sourcedField)
+	for _, source := range sources {
+		for _, userField := range source.Fields {
+			key := fieldKey{userField.Filename, userField.Method, userField.Field}
+			if _, ok := found[key]; !ok {
+				order = append(order, key)
+			}
+			found[key] = append(found[key], sourcedField{source.Name, userField})
+		}
+	}
+
+	result := mergeResult{Fields: make([]fieldsData, 0, len(order)), Conflicts: make([]mergeConflict, 0), Resolved: make([]fieldsData, 0)}
+	for _, key := range order {
+		entries := found[key]
+		fieldConfig, _ := getUserFieldConfig(key.field)
+		candidates := distinctMergeValues(fieldConfig, entries)
+
+		chosen := candidates[0].field
+		if len(candidates) > 1 {
+			if merged, ok := applyMergePolicies(fieldConfig, candidates, policies); ok {
+				chosen = merged
+				result.Resolved = append(result.Resolved, merged)
+			} else {
+				chosen = entries[0].field
+				result.Conflicts = append(result.Conflicts, newMergeConflict(getFilename(key.filename), key.method, key.field, candidates))
+			}
+		}
+
+		version := 0
+		for _, entry := range entries {
+			if entry.field.Version > version {
+				version = entry.field.Version
+			}
+		}
+		if chosen.Value != entries[0].field.Value {
+			version++
+		}
+		chosen.Version = version
+		result.Fields = append(result.Fields, chosen)
+	}
+	return result
+}
+
+// distinctMergeValues keeps the newest entry of every different value, in
+// the order they were found. Unchecked and empty booleans are the same value.
+func distinctMergeValues(fieldConfig UserField, entries []sourcedField) []sourcedField {
+	candidates := make([]sourcedField, 0, len(entries))
+	for _, entry := range entries {
+		same := -1
+		for i, candidate := range candidates {
+			if sameMergeValue(fieldConfig, candidate.field.Value, entry.field.Value) {
+				same = i
+				break
+			}
+		}
+		if same == -1 {
+			candidates = append(candidates, entry)
+		} else if isNewerField(entry.field, candidates[same].field) {
+			candidates[same] = entry
+		}
+	}
+	return candidates
+}
+
+func sameMergeValue(fieldConfig UserField, a string, b string) bool {
+	if fieldConfig.Type == EnumBoolean {
+		return isFieldDone(fieldConfig, a) == isFieldDone(fieldConfig, b)
+	}
+	return a == b
+}
+
+// isNewerField reports whether a was changed after b. Values without date
+// are the oldest.
+func isNewerField(a fieldsData, b fieldsData) bool {
+	if a.UpdatedAt == nil {
+		return false
+	}
+	return b.UpdatedAt == nil || a.UpdatedAt.After(*b.UpdatedAt)
+}
+
+func newestMergeField(candidates []sourcedField) fieldsData {
+	newest := candidates[0].field
+	for _, candidate := range candidates[1:] {
+		if isNewerField(candidate.field, newest) {
+			newest = candidate.field
+		}
+	}
+	return newest
+}
+
+func applyMergePolicies(fieldConfig UserField, candidates []sourcedField, policies []string) (fieldsData, bool) {
+	for _, policy := range policies {
+		switch policy {
+		case "newest":
+			return newestMergeField(candidates), true
+		case "or":
+			if fieldConfig.Type == EnumBoolean {
+				checked := make([]sourcedField, 0, len(candidates))
+				for _, candidate := range candidates {
+					if isFieldDone(fieldConfig, candidate.field.Value) {
+						checked = append(checked, candidate)
+					}
+				}
+				return newestMergeField(checked), true
+			}
+		case "concat":
+			if fieldConfig.Type == EnumTextBox {
+				merged := newestMergeField(candidates)
+				merged.Value = concatMergeValues(candidates)
+				return merged, true
+			}
+		}
+	}
+	return fieldsData{}, false
+}
+
+// concatMergeValues joins the different non empty notes, one per line.
+func concatMergeValues(candidates []sourcedField) string {
+	values := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.field.Value != "" && !containsString(values, candidate.field.Value) {
+			values = append(values, candidate.field.Value)
+		}
+	}
+	return strings.Join(values, "\n")
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newMergeConflict(file string, method string, field string, candidates []sourcedField) mergeConflict {
+	conflict := mergeConflict{File: file, Method: method, Field: field}
+	for _, candidate := range candidates {
+		conflict.Candidates = append(conflict.Candidates, mergeCandidate{
+			Source:    candidate.source,
+			Value:     candidate.field.Value,
+			Reviewer:  candidate.field.Reviewer,
+			UpdatedAt: candidate.field.UpdatedAt,
+		})
+	}
+	return conflict
+}
+
+// localMergeFilename translates the full path stored by another reviewer to
+// the path of the same file in this project, matching the longest relative
+// path that ends the stored one.
+func localMergeFilename(stored string) string {
+	if _, ok := filesData[getFilename(stored)]; ok {
+		return stored
+	}
+
+	normalized := strings.ReplaceAll(stored, "\\", "/")
+	best := ""
+	for name := range filesData {
+		relative := strings.TrimPrefix(name, "/")
+		if strings.HasSuffix(normalized, "/"+relative) && len(relative) > len(strings.TrimPrefix(best, "/")) {
+			best = name
+		}
+	}
+	if best == "" {
+		return stored
+	}
+	return filesData[best].Filename
+}
+
+// applyMergedFields counts the values added or changed by the merge and,
+// when apply is set, stores them and records the changes in the history.
+func applyMergedFields(fields []fieldsData, apply bool) (int, int) {
+	added, updated := 0, 0
+	for _, userField := range fields {
+		current, ok := getUserField(userField.Filename, userField.Method, userField.Field)
+		if ok && current.Value == userField.Value {
+			continue
+		}
+		if ok {
+			updated++
+		} else {
+			added++
+		}
+		if apply {
+			reviewer := userField.Reviewer
+			if reviewer == "" {
+				reviewer = "merge"
+			}
+			recordChange(reviewer, userField.Filename, userField.Method, userField.Field, current.Value, userField.Value)
+		}
+	}
+
+	if apply {
+		setUserFields(fields)
+		checkReviewChanges()
+		markChanged()
+	}
+	return added, updated
+}
+
+func loadMergeConflicts() {
+	conflicts := make([]mergeConflict, 0)
+	data, err := os.ReadFile(path.Join(pathProject, mergeConflictsFilename))
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(logOutput, "Error reading merge conflicts: %v\n", err)
+	} else if err == nil {
+		if err := json.Unmarshal(data, &conflicts); err != nil {
+			fmt.Fprintf(logOutput, "Error decoding merge conflicts: %v\n", err)
+		}
+	}
+
+	mergeConflictsMutex.Lock()
+	mergeConflicts = conflicts
+	mergeConflictsMutex.Unlock()
+
+	if len(conflicts) > 0 {
+		fmt.Fprintf(logOutput, "Warning: %d merge conflict(s) pending, resolve them at /conflicts\n", len(conflicts))
+	}
+}
+
+func getMergeConflicts() []mergeConflict {
+	mergeConflictsMutex.Lock()
+	defer mergeConflictsMutex.Unlock()
+
+	return append([]mergeConflict{}, mergeConflicts...)
+}
+
+// updateMergeConflicts stores new conflicts, replacing the pending ones of
+// the same fields, and drops the pending conflicts of the resolved fields.
+func updateMergeConflicts(conflicts []mergeConflict, resolved []fieldsData) error {
+	mergeConflictsMutex.Lock()
+	defer mergeConflictsMutex.Unlock()
+
+	for _, userField := range resolved {
+		if i := findMergeConflict(getFilename(userField.Filename), userField.Method, userField.Field); i != -1 {
+			mergeConflicts = append(mergeConflicts[:i], mergeConflicts[i+1:]...)
+		}
+	}
+	for _, conflict := range conflicts {
+		if i := findMergeConflict(conflict.File, conflict.Method, conflict.Field); i != -1 {
+			mergeConflicts[i] = conflict
+		} else {
+			mergeConflicts = append(mergeConflicts, conflict)
+		}
+	}
+	return saveMergeConflicts()
+}
+
+// findMergeConflict requires mergeConflictsMutex to be held.
+func findMergeConflict(file string, method string, field string) int {
+	for i, conflict := range mergeConflicts {
+		if conflict.File == file && conflict.Method == method && conflict.Field == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// saveMergeConflicts requires mergeConflictsMutex to be held. The file is
+// removed when there is nothing left to resolve.
+func saveMergeConflicts() error {
+	conflictsPath := path.Join(pathProject, mergeConflictsFilename)
+	if len(mergeConflicts) == 0 {
+		if err := os.Remove(conflictsPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(mergeConflicts, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(conflictsPath, append(data, '\n'))
+}
+
+// resolveMergeConflict stores the chosen value of a conflict as a change of
+// the reviewer and removes the conflict.
+func resolveMergeConflict(file string, method string, field string, value string, reviewer string) error {
+	mergeConflictsMutex.Lock()
+	defer mergeConflictsMutex.Unlock()
+
+	i := findMergeConflict(file, method, field)
+	if i == -1 {
+		return fmt.Errorf("no pending conflict for %s %s %s", file, method, field)
+	}
+
+	data, ok := findFileData(file)
+	if !ok {
+		return fmt.Errorf("file not found: %s", file)
+	}
+
+	if _, err := updateUserField(createFieldName(data.Filename, method, field), value, reviewer, anyVersion); err != nil {
+		return err
+	}
+
+	mergeConflicts = append(mergeConflicts[:i], mergeConflicts[i+1:]...)
+	return saveMergeConflicts()
+}
+
+func getMergeConflictsCount() int {
+	mergeConflictsMutex.Lock()
+	defer mergeConflictsMutex.Unlock()
+
+	return len(mergeConflicts)
+}
+
+func mergeCommand(args []string) int {
+	var policy string
+	var dryRun bool
+
+	flags := newCommandFlags("merge")
+	flags.StringVar(&policy, "policy", "", "conflict policies tried in order: newest, or, concat")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the result without changing any file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	policies, err := parseMergePolicies(policy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Missing the user fields files to merge")
+		return 2
+	}
+
+	if !loadCommandProject() {
+		return 1
+	}
+	if !dryRun && isServerRunning() {
+		fmt.Fprintln(os.Stderr, "The server is running on this project, stop it before merging")
+		return 1
+	}
+
+	userFieldsMutex.Lock()
+	sources := []mergeSource{{Name: userFieldsFilename, Fields: append([]fieldsData{}, userFields...)}}
+	userFieldsMutex.Unlock()
+
+	for _, filename := range flags.Args() {
+		fields, err := readUserFieldsFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", filename, err)
+			return 1
+		}
+		for i := range fields {
+			fields[i].Filename = localMergeFilename(fields[i].Filename)
+		}
+		sources = append(sources, mergeSource{Name: filename, Fields: fields})
+	}
+
+	result := mergeUserFields(sources, policies)
+	for _, conflict := range result.Conflicts {
+		fmt.Printf("Conflict\t%s\t%s\t%s\t%d value(s)\n", conflict.File, conflict.Method, conflict.Field, len(conflict.Candidates))
+	}
+
+	added, updated := applyMergedFields(result.Fields, !dryRun)
+	fmt.Printf("Merged %d file(s): %d added, %d updated, %d conflict(s) resolved, %d pending\n",
+		flags.NArg(), added, updated, len(result.Resolved), len(result.Conflicts))
+	if dryRun {
+		return 0
+	}
+
+	saveFileUserFields()
+	if err := updateMergeConflicts(result.Conflicts, result.Resolved); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving merge conflicts: %v\n", err)
+		return 1
+	}
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("Pending conflicts saved in %s, resolve them at /conflicts\n", mergeConflictsFilename)
+	}
+	return 0
+}
+
+func conflictsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err := resolveMergeConflict(r.Form.Get("file"), r.Form.Get("method"), r.Form.Get("field"), r.Form.Get("value"), getRequestReviewer(r))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, parseEscapeHTML(err.Error()))
+			return
+		}
+
+		http.Redirect(w, r, "/conflicts", http.StatusSeeOther)
+		return
+	}
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+	fmt.Fprintf(w, "<h3>🔀 Merge conflicts</h3>")
+
+	conflicts := getMergeConflicts()
+	if len(conflicts) == 0 {
+		fmt.Fprintf(w, `<p>No pending merge conflicts.</p>`)
+	}
+	now := time.Now()
+	for _, conflict := range conflicts {
+		fieldConfig, _ := getUserFieldConfig(conflict.Field)
+		fmt.Fprint(w, `<div class="file-section">`)
+		fmt.Fprint(w, `<h4>📄 <a href="`+parseEscapeHTML(getFileURL(conflict.File))+`">`+parseEscapeHTML(conflict.File)+`</a></h4>`)
+		fmt.Fprint(w, `<p><code>`+parseEscapeHTML(conflict.Method)+`</code> · `+parseEscapeHTML(conflict.Field)+`</p>`)
+		fmt.Fprint(w, `<table class="report"><tr><th>Value</th><th>Source</th><th>Changed</th><th></th></tr>`)
+		for _, candidate := range conflict.Candidates {
+			changed := getAttributionText(fieldConfig, fieldsData{Value: candidate.Value, Reviewer: candidate.Reviewer, UpdatedAt: candidate.UpdatedAt}, now)
+			if changed == "" {
+				changed = candidate.Reviewer
+			}
+			fmt.Fprint(w, `<tr>`)
+			fmt.Fprint(w, `<td><pre>`+parseEscapeHTML(candidate.Value)+`</pre></td>`)
+			fmt.Fprint(w, `<td>`+parseEscapeHTML(candidate.Source)+`</td>`)
+			fmt.Fprint(w, `<td>`+parseEscapeHTML(changed)+`</td>`)
+			fmt.Fprint(w, `<td>`+getConflictFormHtml(conflict, candidate.Value, "✅ Keep")+`</td>`)
+			fmt.Fprint(w, `</tr>`)
+		}
+		fmt.Fprint(w, `</table>`)
+		if fieldConfig.Type == EnumTextBox {
+			values := make([]string, 0, len(conflict.Candidates))
+			for _, candidate := range conflict.Candidates {
+				if candidate.Value != "" && !containsString(values, candidate.Value) {
+					values = append(values, candidate.Value)
+				}
+			}
+			fmt.Fprint(w, `<div class="actions">`+getConflictFormHtml(conflict, strings.Join(values, "\n"), "📝 Keep all")+`</div>`)
+		}
+		fmt.Fprint(w, `</div>`)
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func getConflictFormHtml(conflict mergeConflict, value string, label string) string {
+	html := `<form method="post" action="/conflicts" class="actions inline">`
+	html += `<input type="hidden" name="file" value="` + parseEscapeHTML(conflict.File) + `">`
+	html += `<input type="hidden" name="method" value="` + parseEscapeHTML(conflict.Method) + `">`
+	html += `<input type="hidden" name="field" value="` + parseEscapeHTML(conflict.Field) + `">`
+	html += `<input type="hidden" name="value" value="` + parseEscapeHTML(value) + `">`
+	html += `<button type="submit">` + label + `</button></form>`
+	return html
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupMergeProject(t *testing.T) {
+	fields := []UserField{
+		{Name: "Checked", Type: EnumBoolean},
+		{Name: "Notes", Type: EnumTextBox},
+		{Name: "Status", Type: EnumChoice, Options: []string{"todo", "done"}},
+	}
+	setupTestProject(t.TempDir()+string(filepath.Separator), fields,
+		testFile{"main.go", []string{"package main", "func a() {", "}"}},
+		testFile{"pkg/util.go", []string{"package pkg", "func b() {", "}"}})
+	mergeConflicts = make([]mergeConflict, 0)
+}
+
+func TestMergeUserFields(t *testing.T) {
+	setupMergeProject(t)
+
+	old := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	recent := old.Add(time.Hour)
+	field := func(name string, value string, reviewer string, when time.Time) fieldsData {
+		return fieldsData{Filename: pathProject + "main.go", Method: "func a() {", Field: name, Value: value, Reviewer: reviewer, UpdatedAt: &when, Version: 1}
+	}
+	sources := []mergeSource{
+		{Name: "mine", Fields: []fieldsData{
+			field("Checked", "0", "Ana", recent),
+			field("Notes", "check nulls", "Ana", old),
+			field("Status", "todo", "Ana", old),
+		}},
+		{Name: "luis.json", Fields: []fieldsData{
+			field("Checked", "1", "Luis", old),
+			field("Notes", "add tests", "Luis", recent),
+			field("Status", "todo", "Luis", recent),
+		}},
+	}
+
+	tests := []struct {
+		policy    string
+		expected  map[string]string
+		conflicts int
+	}{
+		{"", map[string]string{"Checked": "0", "Notes": "check nulls", "Status": "todo"}, 2},
+		{"newest", map[string]string{"Checked": "0", "Notes": "add tests", "Status": "todo"}, 0},
+		{"or", map[string]string{"Checked": "1", "Notes": "check nulls", "Status": "todo"}, 1},
+		{"or,concat", map[string]string{"Checked": "1", "Notes": "check nulls\nadd tests", "Status": "todo"}, 0},
+	}
+
+	for _, test := range tests {
+		policies, err := parseMergePolicies(test.policy)
+		if err != nil {
+			t.Fatalf("parseMergePolicies(%q) failed: %v", test.policy, err)
+		}
+		result := mergeUserFields(sources, policies)
+		if len(result.Conflicts) != test.conflicts || len(result.Resolved) != 2-test.conflicts {
+			t.Errorf("policy %q: %d conflict(s) and %d resolved; expected %d conflict(s)", test.policy, len(result.Conflicts), len(result.Resolved), test.conflicts)
+		}
+		for _, userField := range result.Fields {
+			if userField.Value != test.expected[userField.Field] {
+				t.Errorf("policy %q: %s = %q; expected %q", test.policy, userField.Field, userField.Value, test.expected[userField.Field])
+			}
+		}
+	}
+
+	result := mergeUserFields(sources, nil)
+	if status := result.Fields[2]; status.Reviewer != "Luis" || status.Version != 1 {
+		t.Errorf("Equal values should keep the newest attribution, got %+v", status)
+	}
+	if notes := result.Conflicts[1]; notes.File != "main.go" || len(notes.Candidates) != 2 || notes.Candidates[1].Source != "luis.json" {
+		t.Errorf("Unexpected conflict: %+v", notes)
+	}
+
+	if _, err := parseMergePolicies("newest,last"); err == nil {
+		t.Error("parseMergePolicies should fail on an unknown policy")
+	}
+}
+
+func TestLocalMergeFilename(t *testing.T) {
+	setupMergeProject(t)
+
+	tests := []struct {
+		stored   string
+		expected string
+	}{
+		{pathProject + "main.go", pathProject + "main.go"},
+		{"/home/luis/legacy/pkg/util.go", pathProject + "pkg/util.go"},
+		{`C:\Users\ana\legacy\main.go`, pathProject + "main.go"},
+		{"/home/luis/legacy/other.go", "/home/luis/legacy/other.go"},
+		{"/home/luis/legacy/notmain.go", "/home/luis/legacy/notmain.go"},
+	}
+
+	for _, test := range tests {
+		if filename := localMergeFilename(test.stored); filename != test.expected {
+			t.Errorf("localMergeFilename(%q) = %q; expected %q", test.stored, filename, test.expected)
+		}
+	}
+}
+
+func TestConflictsHandler(t *testing.T) {
+	setupMergeProject(t)
+
+	updated := time.Now().Add(-2 * time.Hour)
+	conflicts := []mergeConflict{
+		{File: "main.go", Method: "func a() {", Field: "Notes", Candidates: []mergeCandidate{
+			{Source: userFieldsFilename, Value: "check nulls"},
+			{Source: "luis.json", Value: "add tests", Reviewer: "Luis", UpdatedAt: &updated},
+		}},
+		{File: "main.go", Method: "func a() {", Field: "Checked", Candidates: []mergeCandidate{
+			{Source: userFieldsFilename, Value: "0"},
+			{Source: "luis.json", Value: "1"},
+		}},
+	}
+	if err := updateMergeConflicts(conflicts, nil); err != nil {
+		t.Fatalf("updateMergeConflicts failed: %v", err)
+	}
+	resolved := []fieldsData{{Filename: pathProject + "main.go", Method: "func a() {", Field: "Checked", Value: "1"}}
+	if err := updateMergeConflicts(nil, resolved); err != nil || getMergeConflictsCount() != 1 {
+		t.Fatalf("Merging again should drop the resolved conflict, got %d (%v)", getMergeConflictsCount(), err)
+	}
+	conflictsPath := filepath.Join(pathProject, mergeConflictsFilename)
+	if _, err := os.Stat(conflictsPath); err != nil {
+		t.Fatalf("Expected the conflicts file: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	conflictsHandler(recorder, httptest.NewRequest(http.MethodGet, "/conflicts", nil))
+	body := recorder.Body.String()
+	for _, expected := range []string{"luis.json", "edited by Luis, 2 hours ago", "value=\"check nulls\nadd tests\""} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Conflicts page should contain %s", expected)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/conflicts", strings.NewReader("file=main.go&method=func+a()+%7B&field=Notes&value=add+tests"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: reviewerCookie, Value: "Ana"})
+	recorder = httptest.NewRecorder()
+	conflictsHandler(recorder, request)
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("Resolving returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	userField, _ := getUserField(pathProject+"main.go", "func a() {", "Notes")
+	if userField.Value != "add tests" || userField.Reviewer != "Ana" {
+		t.Errorf("Unexpected resolved value: %+v", userField)
+	}
+	if getMergeConflictsCount() != 0 {
+		t.Error("The conflict should be removed")
+	}
+	if _, err := os.Stat(conflictsPath); !os.IsNotExist(err) {
+		t.Error("The conflicts file should be removed when empty")
+	}
+}
+
+func TestResolveMergeConflictStoredFilename(t *testing.T) {
+	setupMergeProject(t)
+	// En Windows el nombre guardado usa "\" aunque el relativo use "/"
+	data := filesData["pkg/util.go"]
+	data.Filename = pathProject + `pkg\util.go`
+	filesData["pkg/util.go"] = data
+
+	conflicts := []mergeConflict{
+		{File: "pkg/util.go", Method: "func b() {", Field: "Notes"},
+		{File: "gone.go", Method: "func c() {", Field: "Notes"},
+	}
+	if err := updateMergeConflicts(conflicts, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resolveMergeConflict("pkg/util.go", "func b() {", "Notes", "ok", "Ana"); err != nil {
+		t.Fatalf("resolveMergeConflict failed: %v", err)
+	}
+	if value := getUserValue(pathProject+`pkg\util.go`, "func b() {", "Notes"); value != "ok" {
+		t.Errorf("Expected the value under the stored filename, got %q", value)
+	}
+
+	if err := resolveMergeConflict("gone.go", "func c() {", "Notes", "x", "Ana"); err == nil {
+		t.Error("resolveMergeConflict should fail for a missing file")
+	}
+	if getMergeConflictsCount() != 1 {
+		t.Error("The conflict of a missing file should be kept")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+func migrationHandler(w http.ResponseWriter, r *http.Request) {
+	filename := r.URL.Query().Get("file")
+	data, ok := filesData[filename]
+	if filename != "" && !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+
+	if filename == "" {
+		showMigrationIndexHtml(w)
+	} else {
+		showMigrationFileHtml(w, data)
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func showMigrationIndexHtml(w http.ResponseWriter) {
+	fmt.Fprint(w, "<h3>🔀 Migration</h3>")
+	fmt.Fprint(w, `<div class="file-section"><table class="report">`)
+	fmt.Fprint(w, `<tr><th>File</th><th>Linked methods</th></tr>`)
+	for _, filepath := range projectFiles {
+		filename := getFilename(filepath)
+		data := filesData[filename]
+
+		linked := 0
+		suggested := 0
+		for _, line := range data.Methods {
+			refs, isSuggestion := getMigratedMethods(filename, data.Content[line])
+			if len(refs) > 0 && !isSuggestion {
+				linked++
+			} else if len(refs) > 0 {
+				suggested++
+			}
+		}
+
+		fmt.Fprint(w, `<tr><td><a href="/migration?file=`+url.QueryEscape(filename)+`">`+parseEscapeHTML(filename)+`</a></td>`)
+		fmt.Fprintf(w, `<td>%d / %d (%d suggested)</td></tr>`, linked, len(data.Methods), suggested)
+	}
+	fmt.Fprint(w, `</table></div>`)
+}
+
+func showMigrationFileHtml(w http.ResponseWriter, data fileData) {
+	targetLang := getTargetConfig().LangHighlight
+	filename := getFilename(data.Filename)
+	redirect := "/migration?file=" + url.QueryEscape(filename)
+
+	fmt.Fprint(w, `<h3>🔀 `+parseEscapeHTML(filename)+`</h3>`)
+	for i, line := range data.Methods {
+		method := data.Content[line]
+
+		fmt.Fprint(w, `<div class="file-section">`)
+		fmt.Fprint(w, `<div class="pair">`)
+		fmt.Fprint(w, `<div class="legacy">`)
+		fmt.Fprint(w, getCodeHtml(data.getMethodSegment(i), data.getLangHighlight()))
+		fmt.Fprint(w, `</div><div class="target">`)
+
+		refs, suggested := getMigratedMethods(filename, method)
+		if len(refs) == 0 {
+			fmt.Fprint(w, `<p class="not-migrated">Not migrated yet</p>`)
+		}
+		for _, ref := range refs {
+			fmt.Fprint(w, `<div class="target-file">📄 `+parseEscapeHTML(ref.Filename)+` `)
+			if suggested {
+				fmt.Fprint(w, getLinkFormHtml("link", "🔗 Suggested, link it", filename, method, ref, redirect))
+			} else {
+				fmt.Fprint(w, getLinkFormHtml("unlink", "✖️ Unlink", filename, method, ref, redirect))
+			}
+			fmt.Fprint(w, `</div>`)
+			fmt.Fprint(w, getCodeHtml(getTargetMethodSegment(ref), targetLang))
+		}
+		fmt.Fprint(w, `<form method="post" action="/mappings" class="actions">`)
+		fmt.Fprint(w, `<input type="hidden" name="legacy_file" value="`+parseEscapeHTML(filename)+`">`)
+		fmt.Fprint(w, `<input type="hidden" name="legacy_method" value="`+parseEscapeHTML(method)+`">`)
+		fmt.Fprint(w, `<input type="hidden" name="return" value="`+parseEscapeHTML(redirect)+`">`)
+		fmt.Fprint(w, `<input list="target-methods" name="target" placeholder="Link to target method"> `)
+		fmt.Fprint(w, `<button type="submit" name="action" value="link">🔗 Link</button></form>`)
+		fmt.Fprint(w, `</div></div>`)
+
+		if len(configProject.UserFields) > 0 {
+			fmt.Fprint(w, `<div class="fields">`)
+			fmt.Fprint(w, `<div class="method">`+parseEscapeHTML(method)+`</div><br>`)
+			fmt.Fprint(w, getUserFieldsHtml(data.Filename, method, false))
+			fmt.Fprint(w, `</div>`)
+		}
+		fmt.Fprint(w, `</div>`)
+	}
+
+	fmt.Fprint(w, `<datalist id="target-methods">`)
+	for _, filepath := range targetFiles {
+		target := targetFilesData[getTargetFilename(filepath)]
+		for _, line := range target.Methods {
+			fmt.Fprint(w, `<option value="`+parseEscapeHTML(getTargetFilename(filepath)+`<>`+target.Content[line])+`">`)
+		}
+	}
+	fmt.Fprint(w, `</datalist>`)
+}
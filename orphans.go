@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+// The orphan count shown in every page is cached until a value changes or
+// the project is scanned again.
+var (
+	orphanedCountMutex      sync.Mutex
+	orphanedCount           = -1
+	orphanedCountGeneration int
+)
+
+// isOrphanedField reports whether the file or the method of a stored field
+// no longer exists in the project.
+func isOrphanedField(userField fieldsData) bool {
+	data, ok := filesData[getFilename(userField.Filename)]
+	if !ok {
+		return true
+	}
+	return data.getMethodHash(userField.Method) == ""
+}
+
+func getOrphanedFields() []fieldsData {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	orphaned := make([]fieldsData, 0)
+	for _, userField := range userFields {
+		if isOrphanedField(userField) {
+			orphaned = append(orphaned, userField)
+		}
+	}
+
+	sort.SliceStable(orphaned, func(i, j int) bool {
+		return orphaned[i].Filename < orphaned[j].Filename
+	})
+
+	return orphaned
+}
+
+// deleteOrphanedFields removes the orphaned fields selected by the filter.
+// Returns the number of removed fields.
+func deleteOrphanedFields(selected func(fieldsData) bool) int {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	kept := make([]fieldsData, 0, len(userFields))
+	for _, userField := range userFields {
+		if isOrphanedField(userField) && selected(userField) {
+			continue
+		}
+		kept = append(kept, userField)
+	}
+
+	removed := len(userFields) - len(kept)
+	userFields = kept
+	rebuildUserFieldsIndex()
+	if removed > 0 {
+		markChanged()
+	}
+	return removed
+}
+
+// reassignOrphanedFields moves the orphaned fields selected by the filter to
+// another file of the project. Fields already set in the new file are left
+// orphaned. Returns the number of moved and skipped fields.
+func reassignOrphanedFields(selected func(fieldsData) bool, newFilename string) (int, int, error) {
+	data, ok := filesData[newFilename]
+	if !ok {
+		return 0, 0, fmt.Errorf("file not found in project: %s", newFilename)
+	}
+
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	moved, skipped := 0, 0
+	for i := range userFields {
+		if !isOrphanedField(userFields[i]) || !selected(userFields[i]) {
+			continue
+		}
+		// No se pisa un valor que ya existe en el archivo nuevo
+		if _, ok := userFieldsByKey.find(data.Filename, userFields[i].Method, userFields[i].Field); ok {
+			skipped++
+			continue
+		}
+		userFields[i].Filename = data.Filename
+		userFields[i].Version++
+		userFieldsByKey.add(userFields[i], i)
+		moved++
+	}
+	rebuildUserFieldsIndex()
+
+	if moved > 0 {
+		markChanged()
+	}
+	return moved, skipped, nil
+}
+
+func orphanReason(userField fieldsData) string {
+	if _, ok := filesData[getFilename(userField.Filename)]; !ok {
+		return "file missing"
+	}
+	return "method missing"
+}
+
+func orphansCommand(args []string) int {
+	var filterFile string
+	var deleteFields bool
+	var reassignTo string
+
+	flags := newCommandFlags("orphans")
+	flags.StringVar(&filterFile, "file", "", "only orphaned fields of this file")
+	flags.BoolVar(&deleteFields, "delete", false, "delete the orphaned fields")
+	flags.StringVar(&reassignTo, "reassign", "", "move the orphaned fields to this file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if deleteFields && reassignTo != "" {
+		fmt.Fprintln(os.Stderr, "Use either --delete or --reassign")
+		return 2
+	}
+
+	if !loadCommandProject() {
+		return 1
+	}
+	if (deleteFields || reassignTo != "") && isServerRunning() {
+		fmt.Fprintln(os.Stderr, "The server is running on this project, stop it before changing the orphaned fields")
+		return 1
+	}
+
+	selected := func(userField fieldsData) bool {
+		return filterFile == "" || getFilename(userField.Filename) == filterFile
+	}
+
+	count := 0
+	for _, userField := range getOrphanedFields() {
+		if !selected(userField) {
+			continue
+		}
+		fmt.Printf("%s\t%s\t%s\t%q\t(%s)\n", getFilename(userField.Filename), userField.Method, userField.Field, userField.Value, orphanReason(userField))
+		count++
+	}
+	fmt.Printf("%d orphaned field(s)\n", count)
+
+	if deleteFields {
+		fmt.Printf("Deleted %d field(s)\n", deleteOrphanedFields(selected))
+	} else if reassignTo != "" {
+		moved, skipped, err := reassignOrphanedFields(selected, reassignTo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reassigning fields: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Reassigned %d field(s) to %s\n", moved, reassignTo)
+		if skipped > 0 {
+			fmt.Printf("Skipped %d field(s) already set in %s\n", skipped, reassignTo)
+		}
+	} else {
+		return 0
+	}
+
+	saveFileUserFields()
+	return 0
+}
+
+func orphansHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		names := make(map[string]bool)
+		for _, name := range r.Form["name"] {
+			names[name] = true
+		}
+		selected := func(userField fieldsData) bool {
+			return names[createFieldName(userField.Filename, userField.Method, userField.Field)]
+		}
+
+		switch r.Form.Get("action") {
+		case "delete":
+			deleteOrphanedFields(selected)
+		case "reassign":
+			if _, _, err := reassignOrphanedFields(selected, r.Form.Get("newfile")); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, parseEscapeHTML(err.Error()))
+				return
+			}
+			loadReanchorProposals()
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		http.Redirect(w, r, "/orphans", http.StatusSeeOther)
+		return
+	}
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+	fmt.Fprintf(w, "<h3>🗑️ Orphaned review data</h3>")
+
+	orphaned := getOrphanedFields()
+	if len(orphaned) == 0 {
+		fmt.Fprintf(w, `<p>No orphaned fields.</p>`)
+	} else {
+		fmt.Fprint(w, `<form method="post" action="/orphans" class="file-section">`)
+		fmt.Fprint(w, `<table class="report"><tr><th><input type="checkbox" onchange="selectAll(this)"></th><th>File</th><th>Method</th><th>Field</th><th>Value</th><th>Reason</th></tr>`)
+		for _, userField := range orphaned {
+			fmt.Fprint(w, `<tr>`)
+			fmt.Fprint(w, `<td><input type="checkbox" name="name" value="`+parseEscapeHTML(createFieldName(userField.Filename, userField.Method, userField.Field))+`"></td>`)
+			fmt.Fprint(w, `<td>`+parseEscapeHTML(getFilename(userField.Filename))+`</td>`)
+			fmt.Fprint(w, `<td><code>`+parseEscapeHTML(userField.Method)+`</code></td>`)
+			fmt.Fprint(w, `<td>`+parseEscapeHTML(userField.Field)+`</td>`)
+			fmt.Fprint(w, `<td>`+parseEscapeHTML(userField.Value)+`</td>`)
+			fmt.Fprint(w, `<td>`+orphanReason(userField)+`</td>`)
+			fmt.Fprint(w, `</tr>`)
+		}
+		fmt.Fprint(w, `</table>`)
+
+		fmt.Fprint(w, `<div class="actions">`)
+		fmt.Fprint(w, `<button type="submit" name="action" value="delete" onclick="return confirm('Delete the selected fields?')">🗑️ Delete selected</button> `)
+		fmt.Fprint(w, `<input list="project-files" name="newfile" placeholder="New file path"> `)
+		fmt.Fprint(w, `<button type="submit" name="action" value="reassign">📄 Reassign selected</button>`)
+		fmt.Fprint(w, `<datalist id="project-files">`)
+		for _, filepath := range projectFiles {
+			fmt.Fprint(w, `<option value="`+parseEscapeHTML(getFilename(filepath))+`">`)
+		}
+		fmt.Fprint(w, `</datalist></div></form>`)
+		fmt.Fprint(w, `<script>
+		function selectAll(obj) {
+			document.querySelectorAll('input[name="name"]').forEach(function (c) { c.checked = obj.checked; });
+		}
+		</script>`)
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func getOrphanedCount() int {
+	orphanedCountMutex.Lock()
+	count, generation := orphanedCount, orphanedCountGeneration
+	orphanedCountMutex.Unlock()
+	if count != -1 {
+		return count
+	}
+
+	// Se cuenta sin el mutex: rebuildUserFieldsIndex lo pide con userFieldsMutex tomado
+	count = len(getOrphanedFields())
+
+	orphanedCountMutex.Lock()
+	if generation == orphanedCountGeneration {
+		orphanedCount = count
+	}
+	orphanedCountMutex.Unlock()
+	return count
+}
+
+// resetOrphanedCount makes the next getOrphanedCount count the orphaned
+// fields again.
+func resetOrphanedCount() {
+	orphanedCountMutex.Lock()
+	orphanedCount = -1
+	orphanedCountGeneration++
+	orphanedCountMutex.Unlock()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func setupOrphanedFields() {
+	pathProject = "/test/project"
+	filesData = map[string]fileData{
+		"/file.go": {
+			Filename: "/test/project/file.go",
+			Content:  []string{"package main", "func main() {", "}"},
+			Methods:  []int{1},
+		},
+		"/moved.go": {
+			Filename: "/test/project/moved.go",
+			Content:  []string{"package main", "func helper() {", "}"},
+			Methods:  []int{1},
+		},
+	}
+	setUserFields([]fieldsData{
+		{Filename: "/test/project/file.go", Method: "func main() {", Field: "Checked", Value: "1"},
+		{Filename: "/test/project/file.go", Method: "func removed() {", Field: "Checked", Value: "1"},
+		{Filename: "/test/project/helper.go", Method: "func helper() {", Field: "Checked", Value: "1"},
+		{Filename: "/test/project/helper.go", Method: "func helper() {", Field: "Notes", Value: "todo"},
+	})
+}
+
+func TestGetOrphanedFields(t *testing.T) {
+	setupOrphanedFields()
+
+	orphaned := getOrphanedFields()
+	if len(orphaned) != 3 {
+		t.Fatalf("Expected 3 orphaned fields, got %d", len(orphaned))
+	}
+
+	reasons := map[string]int{}
+	for _, userField := range orphaned {
+		reasons[orphanReason(userField)]++
+	}
+	if reasons["file missing"] != 2 || reasons["method missing"] != 1 {
+		t.Errorf("Unexpected orphan reasons: %v", reasons)
+	}
+}
+
+func TestDeleteOrphanedFields(t *testing.T) {
+	setupOrphanedFields()
+
+	removed := deleteOrphanedFields(func(userField fieldsData) bool {
+		return getFilename(userField.Filename) == "/helper.go"
+	})
+	if removed != 2 {
+		t.Errorf("Expected 2 removed fields, got %d", removed)
+	}
+	if len(userFields) != 2 {
+		t.Errorf("Expected 2 remaining fields, got %d", len(userFields))
+	}
+
+	// Fields that still exist are never removed
+	removed = deleteOrphanedFields(func(fieldsData) bool { return true })
+	if removed != 1 || len(userFields) != 1 || userFields[0].Method != "func main() {" {
+		t.Errorf("Unexpected remaining fields: %+v", userFields)
+	}
+}
+
+func TestReassignOrphanedFields(t *testing.T) {
+	setupOrphanedFields()
+
+	selected := func(userField fieldsData) bool {
+		return getFilename(userField.Filename) == "/helper.go"
+	}
+
+	if _, _, err := reassignOrphanedFields(selected, "/unknown.go"); err == nil {
+		t.Error("Expected error when reassigning to a file outside the project")
+	}
+
+	// The new file already has a value for Checked
+	setUserFields(append(userFields, fieldsData{Filename: "/test/project/moved.go", Method: "func helper() {", Field: "Checked", Value: "0"}))
+	if getOrphanedCount() != 3 {
+		t.Errorf("Expected 3 orphaned fields before reassigning, got %d", getOrphanedCount())
+	}
+
+	moved, skipped, err := reassignOrphanedFields(selected, "/moved.go")
+	if err != nil {
+		t.Fatalf("reassignOrphanedFields() failed: %v", err)
+	}
+	if moved != 1 || skipped != 1 {
+		t.Errorf("Expected 1 moved and 1 skipped field, got %d and %d", moved, skipped)
+	}
+	if moved, _ := getUserField("/test/project/moved.go", "func helper() {", "Notes"); moved.Value != "todo" || moved.Version != 1 {
+		t.Errorf("Expected notes to be moved to the new file with a new version, got %+v", moved)
+	}
+	if getUserValue("/test/project/moved.go", "func helper() {", "Checked") != "0" {
+		t.Error("The value already set in the new file should be kept")
+	}
+	if getOrphanedCount() != 2 {
+		t.Errorf("Expected 2 orphaned fields after reassigning, got %d", getOrphanedCount())
+	}
+}
+
+func TestOrphansCommandWithServerRunning(t *testing.T) {
+	dir := setupStatsProject(t)
+	pathProject = dir
+	holdServerLock(t)
+
+	// func z ya no existe en main.go
+	values := `[{"Filename": "` + dir + `main.go", "Method": "func z() {", "Field": "Checked", "Value": "1"}]`
+	if err := os.WriteFile(dir+userFieldsFilename, []byte(values), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := orphansCommand([]string{"--path", dir}); code != 0 {
+		t.Errorf("Listing with the server running returned %d; expected 0", code)
+	}
+	for _, args := range [][]string{{"--delete"}, {"--reassign", "main.go"}} {
+		if code := orphansCommand(append([]string{"--path", dir}, args...)); code != 1 {
+			t.Errorf("orphans %v with the server running returned %d; expected 1", args, code)
+		}
+	}
+	if after, _ := os.ReadFile(dir + userFieldsFilename); !bytes.Equal(after, []byte(values)) {
+		t.Error("The user fields should not change while the server is running")
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
@@ -33,3 +35,52 @@ func isValidPort(port string) bool {
 	}
 	return true
 }
+
+// writeFileAtomic replaces a JSON file without ever leaving it half written:
+// the data goes to a temporary file in the same folder, is synced to disk and
+// then renamed over the original. The previous version is kept as
+// filename.bak, but only if it is valid JSON, so a damaged file never
+// replaces the good backup it was loaded from.
+func writeFileAtomic(filename string, data []byte) error {
+	if previous, err := os.ReadFile(filename); err == nil && json.Valid(previous) {
+		if err := replaceFile(filename+".bak", previous); err != nil {
+			return err
+		}
+	}
+	return replaceFile(filename, data)
+}
+
+// replaceFile writes the data to a temporary file and renames it over the
+// file, so it is either the old or the new content after a crash.
+func replaceFile(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No hace nada si ya fue renombrado
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+
+	// Sincronizar la carpeta para que el rename sobreviva a un corte de luz.
+	// En Windows no se puede abrir una carpeta para sync, se ignora el error.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+)
+
+type fieldProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func (p fieldProgress) percent() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Done) * 100 / float64(p.Total)
+}
+
+// progressRow holds the progress of the user fields for a file, a
+// directory or the whole project.
+type progressRow struct {
+	Name     string                   `json:"name"`
+	Methods  int                      `json:"methods"`
+	Progress map[string]fieldProgress `json:"progress"`
+}
+
+func newProgressRow(name string) progressRow {
+	return progressRow{Name: name, Progress: make(map[string]fieldProgress)}
+}
+
+func (p *progressRow) add(other progressRow) {
+	p.Methods += other.Methods
+	for field, progress := range other.Progress {
+		current := p.Progress[field]
+		current.Done += progress.Done
+		current.Total += progress.Total
+		p.Progress[field] = current
+	}
+}
+
+func getBooleanFields() []UserField {
+	fields := make([]UserField, 0)
+	for _, field := range configProject.UserFields {
+		if field.Type == EnumBoolean {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// isFieldDone reports whether a value completes the field: checked for
+// boolean fields, not empty for the rest.
+func isFieldDone(field UserField, value string) bool {
+	if field.Type == EnumBoolean {
+		return value == "1"
+	}
+	return value != ""
+}
+
+func getFileProgress(data fileData, fields []UserField) progressRow {
+	row := newProgressRow(getFilename(data.Filename))
+	row.Methods = len(data.Methods)
+	for _, field := range fields {
+		progress := fieldProgress{Total: len(data.Methods)}
+		for _, line := range data.Methods {
+			if isFieldDone(field, getUserValue(data.Filename, data.Content[line], field.Name)) {
+				progress.Done++
+			}
+		}
+		row.Progress[field.Name] = progress
+	}
+	return row
+}
+
+// computeProgress returns the progress of the fields per file, per directory
+// and for the whole project. Each directory includes its subdirectories.
+func computeProgress(fields []UserField) ([]progressRow, []progressRow, progressRow) {
+	files := make([]progressRow, 0, len(projectFiles))
+	dirs := make(map[string]*progressRow)
+	project := newProgressRow(configProject.ProjectName)
+	for _, field := range fields {
+		project.Progress[field.Name] = fieldProgress{}
+	}
+
+	for _, filepath := range projectFiles {
+		row := getFileProgress(filesData[getFilename(filepath)], fields)
+		files = append(files, row)
+
+		for dir := path.Dir(row.Name); ; dir = path.Dir(dir) {
+			if _, ok := dirs[dir]; !ok {
+				dirRow := newProgressRow(dir)
+				dirs[dir] = &dirRow
+			}
+			dirs[dir].add(row)
+			if dir == "." || dir == "/" {
+				break
+			}
+		}
+		project.add(row)
+	}
+
+	dirRows := make([]progressRow, 0, len(dirs))
+	for _, row := range dirs {
+		dirRows = append(dirRows, *row)
+	}
+	sort.Slice(dirRows, func(i, j int) bool {
+		return dirRows[i].Name < dirRows[j].Name
+	})
+
+	return files, dirRows, project
+}
+
+func dashboardHandler(w http.ResponseWriter, r *http.Request) {
+	fields := getBooleanFields()
+	files, dirs, project := computeProgress(fields)
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+	fmt.Fprint(w, "<h3>📊 Dashboard</h3>")
+	fmt.Fprint(w, getReportLinksHtml())
+
+	if len(fields) == 0 {
+		fmt.Fprint(w, `<p>There are no boolean fields in the config.</p>`)
+	} else {
+		fmt.Fprint(w, `<div class="file-section"><div class="summary">`)
+		for _, field := range fields {
+			progress := project.Progress[field.Name]
+			fmt.Fprint(w, `<div class="summary-field"><h4>`+parseEscapeHTML(field.Name)+`</h4>`)
+			fmt.Fprint(w, getProgressHtml(progress))
+			if progress.Done < progress.Total {
+				fmt.Fprint(w, `<br><a href="`+parseEscapeHTML(getReviewURL("!"+quoteFilterWord(field.Name)))+`">📝 Review pending</a>`)
+			}
+			fmt.Fprint(w, `</div>`)
+		}
+		fmt.Fprint(w, `</div></div>`)
+
+		fmt.Fprint(w, "<h3>📁 Directories</h3>")
+		showProgressTableHtml(w, dirs, fields, false)
+
+		fmt.Fprint(w, "<h3>📄 Files</h3>")
+		showProgressTableHtml(w, files, fields, true)
+
+		fmt.Fprint(w, `<script>
+		function sortTable(th) {
+			var table = th.closest("table");
+			var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+			var asc = th.dataset.order != "asc";
+			th.parentNode.querySelectorAll("th").forEach(function (h) { delete h.dataset.order; });
+			th.dataset.order = asc ? "asc" : "desc";
+			var rows = Array.prototype.slice.call(table.querySelectorAll("tr")).slice(1);
+			rows.sort(function (a, b) {
+				var x = a.children[index].dataset.value, y = b.children[index].dataset.value;
+				var cmp = isNaN(x) || isNaN(y) ? x.localeCompare(y) : x - y;
+				return asc ? cmp : -cmp;
+			});
+			rows.forEach(function (row) { table.appendChild(row); });
+		}
+		</script>`)
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func showProgressTableHtml(w http.ResponseWriter, rows []progressRow, fields []UserField, linkFiles bool) {
+	fmt.Fprint(w, `<div class="file-section"><table class="report sortable">`)
+	fmt.Fprint(w, `<tr><th onclick="sortTable(this)">Name</th><th onclick="sortTable(this)">Methods</th>`)
+	for _, field := range fields {
+		fmt.Fprint(w, `<th onclick="sortTable(this)">`+parseEscapeHTML(field.Name)+`</th>`)
+	}
+	fmt.Fprint(w, `</tr>`)
+
+	for _, row := range rows {
+		name := parseEscapeHTML(row.Name)
+		if linkFiles {
+			name = `<a href="` + parseEscapeHTML(serverPage.FileLink(row.Name)) + `">` + name + `</a>`
+		}
+		fmt.Fprint(w, `<tr><td data-value="`+parseEscapeHTML(row.Name)+`">`+name+`</td>`)
+		fmt.Fprintf(w, `<td data-value="%d">%d</td>`, row.Methods, row.Methods)
+		for _, field := range fields {
+			progress := row.Progress[field.Name]
+			fmt.Fprintf(w, `<td data-value="%.2f">`, progress.percent())
+			fmt.Fprint(w, getProgressHtml(progress))
+			fmt.Fprint(w, `</td>`)
+		}
+		fmt.Fprint(w, `</tr>`)
+	}
+	fmt.Fprint(w, `</table></div>`)
+}
+
+func getProgressHtml(progress fieldProgress) string {
+	return fmt.Sprintf(`<div class="progress"><div class="bar" style="width: %.0f%%"></div></div><span>%d / %d (%.1f%%)</span>`,
+		progress.percent(), progress.Done, progress.Total, progress.percent())
+}
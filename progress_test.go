@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestComputeProgress(t *testing.T) {
+	// Setup
+	pathProject = "/test/project/"
+	configProject = config{
+		ProjectName: "Test",
+		UserFields: []UserField{
+			{Name: "Checked", Type: EnumBoolean},
+			{Name: "Notes", Type: EnumTextBox},
+		},
+	}
+	projectFiles = []string{"/test/project/main.go", "/test/project/utils/a.go", "/test/project/utils/b.go", "/test/project/utils/sub/c.go"}
+	filesData = map[string]fileData{
+		"main.go": {
+			Filename: "/test/project/main.go",
+			Content:  []string{"func main() {", "}", "func init() {", "}"},
+			Methods:  []int{0, 2},
+		},
+		"utils/a.go": {
+			Filename: "/test/project/utils/a.go",
+			Content:  []string{"func a() {", "}"},
+			Methods:  []int{0},
+		},
+		"utils/b.go": {
+			Filename: "/test/project/utils/b.go",
+			Content:  []string{"func b() {", "}"},
+			Methods:  []int{0},
+		},
+		"utils/sub/c.go": {
+			Filename: "/test/project/utils/sub/c.go",
+			Content:  []string{"func c() {", "}"},
+			Methods:  []int{0},
+		},
+	}
+	setUserFields([]fieldsData{
+		{Filename: "/test/project/main.go", Method: "func main() {", Field: "Checked", Value: "1"},
+		{Filename: "/test/project/main.go", Method: "func init() {", Field: "Checked", Value: "0"},
+		{Filename: "/test/project/utils/a.go", Method: "func a() {", Field: "Checked", Value: "1"},
+		{Filename: "/test/project/utils/a.go", Method: "func a() {", Field: "Notes", Value: "1"},
+		{Filename: "/test/project/utils/sub/c.go", Method: "func c() {", Field: "Checked", Value: "1"},
+	})
+
+	files, dirs, project := computeProgress(getBooleanFields())
+
+	if len(files) != 4 {
+		t.Fatalf("Expected 4 file rows, got %d", len(files))
+	}
+	if progress := files[0].Progress["Checked"]; progress.Done != 1 || progress.Total != 2 {
+		t.Errorf("Unexpected progress for main.go: %+v", progress)
+	}
+	if _, ok := files[0].Progress["Notes"]; ok {
+		t.Error("Non boolean fields should not be in the progress")
+	}
+
+	if len(dirs) != 3 || dirs[0].Name != "." || dirs[1].Name != "utils" || dirs[2].Name != "utils/sub" {
+		t.Fatalf("Unexpected directory rows: %+v", dirs)
+	}
+	// Cada carpeta suma las subcarpetas
+	expected := []fieldProgress{{3, 5}, {2, 3}, {1, 1}}
+	for i, dir := range dirs {
+		if progress := dir.Progress["Checked"]; progress != expected[i] {
+			t.Errorf("Unexpected progress for %s: %+v; expected %+v", dir.Name, progress, expected[i])
+		}
+	}
+
+	progress := project.Progress["Checked"]
+	if progress.Done != 3 || progress.Total != 5 || project.Methods != 5 {
+		t.Errorf("Unexpected project progress: %+v (%d methods)", progress, project.Methods)
+	}
+	if progress.percent() != 60 {
+		t.Errorf("Expected 60%%, got %.2f", progress.percent())
+	}
+}
+
+func TestIsFieldDone(t *testing.T) {
+	tests := []struct {
+		field    UserField
+		value    string
+		expected bool
+	}{
+		{UserField{Name: "Checked", Type: EnumBoolean}, "1", true},
+		{UserField{Name: "Checked", Type: EnumBoolean}, "0", false},
+		{UserField{Name: "Checked", Type: EnumBoolean}, "", false},
+		{UserField{Name: "Notes", Type: EnumTextBox}, "todo", true},
+		{UserField{Name: "Notes", Type: EnumTextBox}, "", false},
+		{UserField{Name: "Status", Type: EnumChoice}, "Pending", true},
+	}
+
+	for _, test := range tests {
+		result := isFieldDone(test.field, test.value)
+		if result != test.expected {
+			t.Errorf("isFieldDone(%s, %s) = %v; expected %v", test.field.Name, test.value, result, test.expected)
+		}
+	}
+}
+
+func TestFieldProgressPercentEmpty(t *testing.T) {
+	if percent := (fieldProgress{}).percent(); percent != 0 {
+		t.Errorf("Expected 0%% without methods, got %.2f", percent)
+	}
+}
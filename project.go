@@ -2,14 +2,17 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 )
 
 type fileData struct {
@@ -27,44 +30,107 @@ var (
 )
 
 func (f fileData) getContentHTMLWithFields() string {
-	var content string = ""
+	return f.getContentHtml(false)
+}
+
+func (f fileData) getContentHtml(readOnly bool) string {
+	var content strings.Builder
 	var prevMethod int = 0
+	lang := f.getLangHighlight()
 	for _, method := range f.Methods {
-		content += `<pre>`
-		if configProject.LangHighlight != "" {
-			content += `<code class="` + configProject.LangHighlight + `">`
-		} else {
-			content += `<code>`
+		content.WriteString(getCodeHtml(f.Content[prevMethod:method], lang))
+		content.WriteString(`<div id="` + getMethodAnchor(method) + `" class="mark"></div>`)
+
+		content.WriteString(getMethodFieldsHtml(f.Filename, f.Content[method], readOnly))
+		prevMethod = method
+	}
+	// El último método llega hasta el final del archivo
+	content.WriteString(getCodeHtml(f.Content[prevMethod:], lang))
+
+	return content.String()
+}
+
+func getMethodFieldsHtml(filename string, method string, readOnly bool) string {
+	if len(configProject.UserFields) == 0 {
+		return ""
+	}
+
+	var content string = `<div class="fields">`
+	content += `<div class="method">` + parseEscapeHTML(method) + `</div>`
+	if methodNeedsReview(filename, method) {
+		content += `<div class="needs-review">⚠️ Changed since last review</div>`
+	}
+	content += `<br>`
+	content += getUserFieldsHtml(filename, method, readOnly)
+	if !readOnly {
+		content += `<details class="history" data-file="` + parseEscapeHTML(getFilename(filename)) + `" data-method="` + parseEscapeHTML(method) + `" ontoggle="loadHistory(this)">`
+		content += `<summary>🕓 History</summary><div class="history-list"></div></details>`
+	}
+	content += `</div>`
+	return content
+}
+
+func getCodeHtml(lines []string, lang string) string {
+	var content string = `<pre>`
+	if lang != "" {
+		content += `<code class="` + lang + `">`
+	} else {
+		content += `<code>`
+	}
+	content += parseEscapeHTML(strings.Join(lines, "\n"))
+	content += `</code></pre>`
+	return content
+}
+
+func getUserFieldsHtml(filename string, method string, readOnly bool) string {
+	var onChange string = ` onchange="saveChange(this)"`
+	if readOnly {
+		onChange = ` disabled`
+	}
+
+	var content string = ""
+	for _, field := range configProject.UserFields {
+		userField, stored := getUserField(filename, method, field.Name)
+		value := userField.Value
+		attrs := onChange
+		if !readOnly {
+			// La versión permite rechazar cambios sobre un valor que otro ya modificó
+			attrs += ` data-version="` + strconv.Itoa(userField.Version) + `"`
 		}
-		content += parseEscapeHTML(strings.Join(f.Content[prevMethod:method], "\n"))
-		content += `</code></pre>`
-
-		if len(configProject.UserFields) > 0 {
-			content += `<div class="fields">`
-			content += `<div class="method">` + parseEscapeHTML(f.Content[method]) + `</div><br>`
-			for _, field := range configProject.UserFields {
-				content += `<div class="field">`
-				fieldNameEscaped := parseEscapeHTML(field.Name)
-				fieldNameAttr := parseEscapeHTML(createFieldName(f.Filename, f.Content[method], field.Name))
-				if field.Type == EnumBoolean {
-					content += `<label><input type="checkbox" name="` + fieldNameAttr + `" value="` + fieldNameEscaped + `" `
-					if getUserValue(f.Filename, f.Content[method], field.Name) == "1" {
-						content += `checked`
-					}
-					content += ` onchange="saveChange(this)"> ` + fieldNameEscaped + `</label>`
-				} else if field.Type == EnumTextBox {
-					content += `<label>` + fieldNameEscaped + `<br/><textarea name="` + fieldNameAttr + `" onchange="saveChange(this)">`
-					content += parseEscapeHTML(getUserValue(f.Filename, f.Content[method], field.Name))
-					content += `</textarea></label>`
+
+		content += `<div class="field">`
+		fieldNameEscaped := parseEscapeHTML(field.Name)
+		fieldNameAttr := parseEscapeHTML(createFieldName(filename, method, field.Name))
+		if field.Type == EnumBoolean {
+			content += `<label><input type="checkbox" name="` + fieldNameAttr + `" value="` + fieldNameEscaped + `" `
+			if value == "1" {
+				content += `checked`
+			}
+			content += attrs + `> ` + fieldNameEscaped + `</label>`
+		} else if field.Type == EnumTextBox {
+			content += `<label>` + fieldNameEscaped + `<br/><textarea name="` + fieldNameAttr + `"` + attrs + `>`
+			content += parseEscapeHTML(value)
+			content += `</textarea></label>`
+		} else if field.Type == EnumChoice {
+			content += `<label>` + fieldNameEscaped + `<br/><select name="` + fieldNameAttr + `"` + attrs + `>`
+			content += `<option value=""></option>`
+			for _, option := range field.Options {
+				optionEscaped := parseEscapeHTML(option)
+				content += `<option value="` + optionEscaped + `"`
+				if option == value {
+					content += ` selected`
 				}
-				content += `</div>`
+				content += `>` + optionEscaped + `</option>`
 			}
-
-			content += `</div>`
+			content += `</select></label>`
 		}
-		prevMethod = method
+		if stored {
+			if attribution := getAttributionText(field, userField, time.Now()); attribution != "" {
+				content += `<div class="attribution">` + parseEscapeHTML(attribution) + `</div>`
+			}
+		}
+		content += `</div>`
 	}
-
 	return content
 }
 
@@ -72,14 +138,48 @@ func (f fileData) getContent() string {
 	return strings.Join(f.Content, "\n")
 }
 
+// getMethodSegment returns the lines from the method header up to the next
+// method header (or the end of the file).
+func (f fileData) getMethodSegment(index int) []string {
+	end := len(f.Content)
+	if index+1 < len(f.Methods) {
+		end = f.Methods[index+1]
+	}
+	return f.Content[f.Methods[index]:end]
+}
+
+func (f fileData) getMethodHash(method string) string {
+	for i, line := range f.Methods {
+		if f.Content[line] == method {
+			sum := sha256.Sum256([]byte(strings.Join(f.getMethodSegment(i), "\n")))
+			return hex.EncodeToString(sum[:])
+		}
+	}
+	return ""
+}
+
+// getMethodHash returns the content hash of a method, or empty if the file or
+// the method doesn't exist in the project.
+func getMethodHash(filename string, method string) string {
+	data, ok := filesData[getFilename(filename)]
+	if !ok {
+		return ""
+	}
+	return data.getMethodHash(method)
+}
+
 func getFilename(filepath string) string {
-	filename := strings.ReplaceAll(filepath, pathProject, "")
+	return relativeFilename(pathProject, filepath)
+}
+
+func relativeFilename(root string, filepath string) string {
+	filename := strings.ReplaceAll(filepath, root, "")
 	filename = strings.ReplaceAll(filename, "\\", "/")
 	return filename
 }
 
 func loadProject() bool {
-	fmt.Println("Project path:", pathProject)
+	fmt.Fprintln(logOutput, "Project path:", pathProject)
 
 	if !loadConfig() {
 		return false
@@ -88,7 +188,10 @@ func loadProject() bool {
 	lastChange = time.Now()
 	lastSave = lastChange
 
-	loadUserFields()
+	if !loadUserFields() {
+		fmt.Fprintln(logOutput, "Fix or remove the user fields file to continue")
+		return false
+	}
 
 	projectFiles = make([]string, 0)
 	filesData = make(map[string]fileData)
@@ -97,15 +200,30 @@ func loadProject() bool {
 
 	projectFiles, err = scanProject(pathProject, nil)
 	if err != nil {
-		fmt.Printf("Error scanning project: %v\n", err)
+		fmt.Fprintf(logOutput, "Error scanning project: %v\n", err)
 		return false
 	}
 
-	fmt.Printf("Project loaded: %d file(s) found\n", len(projectFiles))
+	if updated := checkReviewChanges(); updated > 0 {
+		markChanged()
+	}
+	if changed := countMethodsNeedingReview(); changed > 0 {
+		fmt.Fprintf(logOutput, "Warning: %d reviewed method(s) changed since last review\n", changed)
+	}
+	loadReanchorProposals()
+	loadMergeConflicts()
+	resetOrphanedCount()
+
+	fmt.Fprintf(logOutput, "Project loaded: %d file(s) found\n", len(projectFiles))
 	return true
 }
 
+// isExtFilter reports whether the file is loaded: its extension is in the
+// filter or has a language profile.
 func isExtFilter(filename string) bool {
+	if _, ok := configProject.Languages[filepath.Ext(filename)]; ok {
+		return true
+	}
 	for _, ext := range configProject.ExtFilter {
 		if filepath.Ext(filename) == ext {
 			return true
@@ -115,60 +233,80 @@ func isExtFilter(filename string) bool {
 }
 
 func loadFileData(filename string) error {
+	data, err := readFileData(filename, getFileProfile(filename))
+	if err != nil {
+		return err
+	}
+
+	filesData[getFilename(filename)] = data
+	return nil
+}
+
+// readFileData reads a source file and finds its methods with the regular
+// expressions of the profile, skipping the commented out lines.
+func readFileData(filename string, profile fileProfile) (fileData, error) {
 	const maxFileSize = 10 * 1024 * 1024 // 10MB limit
 
 	file, err := os.Open(filename)
 	if err != nil {
-		return err
+		return fileData{}, err
 	}
 	defer file.Close()
 
 	stat, err := file.Stat()
 	if err != nil {
-		return err
+		return fileData{}, err
 	}
 
 	if stat.Size() > maxFileSize {
-		return fmt.Errorf("file too large: %s (size: %d, max: %d)", filename, stat.Size(), maxFileSize)
+		return fileData{}, fmt.Errorf("file too large: %s (size: %d, max: %d)", filename, stat.Size(), maxFileSize)
 	}
 
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return err
+		return fileData{}, err
 	}
 
-	fileString := string(data)
-
-	charsetUFT8 := utf8.ValidString(fileString)
-	charsetANSI := !charsetUFT8
-
-	if charsetANSI {
-		fileString = fromWindows1252(fileString)
-	}
+	fileString := profile.decode(data)
 
 	content := []string{}
 	methods := []int{}
 
+	inComment := false
 	for i, line := range strings.Split(fileString, "\n") {
 		content = append(content, line)
-		for _, re := range methodFilterRegexes {
-			if re.MatchString(line) {
+		var code string
+		code, inComment = profile.stripComments(line, inComment)
+		for _, re := range profile.MethodRegexes {
+			if re.MatchString(code) {
 				methods = append(methods, i)
 				break // Solo necesitamos que coincida con un patrón
 			}
 		}
 	}
 
-	filesData[getFilename(filename)] = fileData{
+	return fileData{
 		Filename: filename,
 		Content:  content,
 		Methods:  methods,
-	}
-
-	return nil
+	}, nil
 }
 
+// scanProject loads the project files, skipping the ones excluded by the
+// patterns of the config and the .gitignore files.
 func scanProject(root string, list []string) ([]string, error) {
+	filter := newScanFilter(root, configProject.Include, configProject.Exclude, configProject.GitIgnore)
+	files, err := scanTreeFiltered(root, list, isExtFilter, loadFileData, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.logExclusions()
+	return files, nil
+}
+
+// scanTreeFiltered walks the root folder loading every file accepted by
+// include and not excluded by the filter.
+func scanTreeFiltered(root string, list []string, include func(string) bool, load func(string) error, filter *scanFilter) ([]string, error) {
 	var filesOut []string = list
 	var filesTmp []string
 
@@ -179,9 +317,9 @@ func scanProject(root string, list []string) ([]string, error) {
 
 	for _, f := range files {
 		if !f.IsDir() {
-			if isExtFilter(f.Name()) {
+			if include(f.Name()) && !filter.skipFile(f.Name()) {
 				filesOut = append(filesOut, path.Join(root+f.Name()))
-				if err := loadFileData(path.Join(root + f.Name())); err != nil {
+				if err := load(path.Join(root + f.Name())); err != nil {
 					return nil, err
 				}
 			}
@@ -190,7 +328,12 @@ func scanProject(root string, list []string) ([]string, error) {
 
 	for _, f := range files {
 		if f.IsDir() {
-			filesTmp, err = scanProject(path.Join(root, f.Name())+string(filepath.Separator), nil)
+			dirPath := path.Join(root, f.Name()) + string(filepath.Separator)
+			child, ok := filter.enterDir(dirPath, f.Name())
+			if !ok {
+				continue
+			}
+			filesTmp, err = scanTreeFiltered(dirPath, nil, include, load, child)
 			if err != nil {
 				return nil, err
 			}
@@ -201,17 +344,39 @@ func scanProject(root string, list []string) ([]string, error) {
 	return filesOut, nil
 }
 
-func changedUserField(name string, value string) bool {
+func changedUserField(name string, value string, reviewer string) bool {
+	_, err := updateUserField(name, value, reviewer, anyVersion)
+	return err == nil
+}
+
+// errStaleValue is returned by updateUserField when someone else changed the
+// value after the reviewer loaded it.
+var errStaleValue = errors.New("the value was changed by someone else")
+
+// updateUserField validates and stores the change of a field. It returns the
+// stored value, or the current one with errStaleValue if version is not the
+// current version of the value.
+func updateUserField(name string, value string, reviewer string, version int) (fieldsData, error) {
 	filename, method, field := disassemblyFieldName(name)
 	if filename == "" || method == "" || field == "" {
-		fmt.Printf("Invalid field name format: %s\n", name)
-		return false
+		fmt.Fprintf(logOutput, "Invalid field name format: %s\n", name)
+		return fieldsData{}, fmt.Errorf("invalid field name format: %s", name)
 	}
 
-	setUserValue(filename, method, field, value)
-	lastChange = time.Now()
+	if fieldConfig, ok := getUserFieldConfig(field); ok && !fieldConfig.isValidValue(value) {
+		fmt.Fprintf(logOutput, "Invalid value for field %s: %s\n", field, value)
+		return fieldsData{}, fmt.Errorf("invalid value for field %s: %s", field, value)
+	}
 
-	return true
+	oldValue, current, ok := setUserValueBy(filename, method, field, value, reviewer, time.Now(), version)
+	if !ok {
+		return current, errStaleValue
+	}
+	recordChange(reviewer, filename, method, field, oldValue, value)
+	markMethodReviewed(filename, method, getMethodHash(filename, method))
+	markChanged()
+
+	return current, nil
 }
 
 func fromWindows1252(str string) string {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -8,6 +9,33 @@ import (
 	"testing"
 )
 
+// testFile is a file of the fixture project, relative to the project path.
+// Its methods are the lines starting with "func ".
+type testFile struct {
+	Name    string
+	Content []string
+}
+
+// setupTestProject loads a project in memory with the fields and files
+// given and no stored values.
+func setupTestProject(root string, fields []UserField, files ...testFile) {
+	pathProject = root
+	configProject = config{UserFields: fields}
+	projectFiles = make([]string, 0, len(files))
+	filesData = make(map[string]fileData)
+	for _, file := range files {
+		data := fileData{Filename: root + file.Name, Content: file.Content, Methods: make([]int, 0)}
+		for i, line := range file.Content {
+			if strings.HasPrefix(line, "func ") {
+				data.Methods = append(data.Methods, i)
+			}
+		}
+		projectFiles = append(projectFiles, data.Filename)
+		filesData[file.Name] = data
+	}
+	setUserFields(make([]fieldsData, 0))
+}
+
 func TestGetFilename(t *testing.T) {
 	// Setup
 	pathProject = "/test/project"
@@ -159,7 +187,7 @@ func TestChangedUserField(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := changedUserField(test.name, test.value)
+		result := changedUserField(test.name, test.value, "")
 
 		if test.expectErr && result {
 			t.Errorf("changedUserField(%s, %s) expected error but got success", test.name, test.value)
@@ -243,3 +271,155 @@ func helper() {}`,
 		t.Errorf("Expected at least %d entries in filesData, got %d", expectedCount, len(filesData))
 	}
 }
+
+func TestChangedUserFieldChoice(t *testing.T) {
+	// Setup
+	pathProject = "/test/project"
+	setUserFields(make([]fieldsData, 0))
+	configProject = config{
+		UserFields: []UserField{
+			{Name: "Status", Type: EnumChoice, Options: []string{"Pending", "Migrated"}},
+		},
+	}
+
+	tests := []struct {
+		value     string
+		expectErr bool
+	}{
+		{"Pending", false},
+		{"Migrated", false},
+		{"", false},        // Clearing the choice
+		{"Unknown", true},  // Not an option
+		{"migrated", true}, // Options are case sensitive
+	}
+
+	for _, test := range tests {
+		result := changedUserField("file.go<>main<>Status", test.value, "")
+		if test.expectErr && result {
+			t.Errorf("changedUserField(Status, %s) expected error but got success", test.value)
+		}
+		if !test.expectErr && !result {
+			t.Errorf("changedUserField(Status, %s) expected success but got error", test.value)
+		}
+	}
+
+	if value := getUserValue("file.go", "main", "Status"); value != "" {
+		t.Errorf("Expected cleared value, got '%s'", value)
+	}
+}
+
+func TestGetContentHTMLWithFieldsChoice(t *testing.T) {
+	// Setup
+	setUserFields([]fieldsData{
+		{Filename: "file.go", Method: "func main() {", Field: "Status", Value: "Migrated"},
+	})
+	configProject = config{
+		UserFields: []UserField{
+			{Name: "Status", Type: EnumChoice, Options: []string{"Pending", "Migrated"}},
+		},
+	}
+
+	data := fileData{
+		Filename: "file.go",
+		Content:  []string{"package main", "", "func main() {", "}"},
+		Methods:  []int{2},
+	}
+
+	content := data.getContentHTMLWithFields()
+
+	if !strings.Contains(content, `<select name="file.go&lt;&gt;func main() {&lt;&gt;Status"`) {
+		t.Errorf("Expected select for choice field, got %s", content)
+	}
+	if !strings.Contains(content, `<option value="Migrated" selected>Migrated</option>`) {
+		t.Errorf("Expected stored option to be selected, got %s", content)
+	}
+	if !strings.Contains(content, `<option value="Pending">Pending</option>`) {
+		t.Errorf("Expected unselected option, got %s", content)
+	}
+}
+
+func TestGetContentHtmlLastMethod(t *testing.T) {
+	configProject = config{UserFields: []UserField{{Name: "Checked", Type: EnumBoolean}}}
+	setUserFields(make([]fieldsData, 0))
+
+	tests := []struct {
+		data     fileData
+		expected []string
+	}{
+		{fileData{Filename: "a.go", Content: []string{"package a", "func One() {", "\treturn 1", "}"}, Methods: []int{1}},
+			[]string{"<code>package a</code>", "<code>func One() {\n\treturn 1\n}</code>"}},
+		{fileData{Filename: "b.go", Content: []string{"package b", "var x = 1"}, Methods: []int{}},
+			[]string{"<code>package b\nvar x = 1</code>"}},
+	}
+
+	for _, test := range tests {
+		content := test.data.getContentHtml(true)
+		for _, expected := range test.expected {
+			if !strings.Contains(content, expected) {
+				t.Errorf("%s should contain %q, got %s", test.data.Filename, expected, content)
+			}
+		}
+	}
+}
+
+func TestGetMethodHash(t *testing.T) {
+	data := fileData{
+		Filename: "file.go",
+		Content:  []string{"package main", "func a() {", "}", "func b() {", "}"},
+		Methods:  []int{1, 3},
+	}
+
+	hashA := data.getMethodHash("func a() {")
+	hashB := data.getMethodHash("func b() {")
+	if hashA == "" || hashB == "" {
+		t.Fatal("Expected hashes for existing methods")
+	}
+	if hashA == hashB {
+		t.Error("Expected different hashes for different methods")
+	}
+	if hash := data.getMethodHash("func c() {"); hash != "" {
+		t.Errorf("Expected empty hash for missing method, got %s", hash)
+	}
+
+	// Changing the body of a method changes only its hash
+	data.Content = []string{"package main", "func a() {", "\treturn", "}", "func b() {", "}"}
+	data.Methods = []int{1, 4}
+	if data.getMethodHash("func a() {") == hashA {
+		t.Error("Expected hash to change when the method body changes")
+	}
+	if data.getMethodHash("func b() {") != hashB {
+		t.Error("Expected hash to stay the same for an unchanged method")
+	}
+}
+
+// BenchmarkGetContentHtml renders files with a growing number of methods and
+// stored values. The time per method must stay flat as the project grows.
+func BenchmarkGetContentHtml(b *testing.B) {
+	configProject = config{
+		UserFields: []UserField{
+			{Name: "Checked", Type: EnumBoolean},
+			{Name: "Notes", Type: EnumTextBox},
+		},
+	}
+
+	for _, methods := range []int{1000, 5000, 20000} {
+		data := fileData{Filename: "file.go"}
+		fields := make([]fieldsData, 0, methods*2)
+		for i := 0; i < methods; i++ {
+			header := fmt.Sprintf("func m%d() {", i)
+			data.Methods = append(data.Methods, len(data.Content))
+			data.Content = append(data.Content, header, "\treturn", "}")
+			fields = append(fields,
+				fieldsData{Filename: "file.go", Method: header, Field: "Checked", Value: "1"},
+				fieldsData{Filename: "file.go", Method: header, Field: "Notes", Value: "reviewed"})
+		}
+		setUserFields(fields)
+
+		b.Run(fmt.Sprintf("methods=%d/values=%d", methods, len(fields)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				data.getContentHtml(false)
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*methods), "ns/method")
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Minimum similarity to propose a new header for an orphaned method
+	reanchorMinScore = 0.6
+)
+
+type reanchorProposal struct {
+	Filename  string
+	OldMethod string
+	NewMethod string
+	Score     float64
+}
+
+// reanchorDiscard is a proposal rejected by a reviewer, not proposed again.
+// The file is relative to the project.
+type reanchorDiscard struct {
+	File      string
+	OldMethod string
+	NewMethod string
+}
+
+var (
+	reanchorProposals      []reanchorProposal
+	reanchorDiscarded      []reanchorDiscard
+	reanchorProposalsMutex sync.Mutex
+
+	methodNameRegex = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+	// Palabras que preceden al nombre del método y no deben tomarse como nombre
+	methodKeywords = map[string]bool{
+		"func": true, "function": true, "sub": true, "def": true,
+		"property": true, "get": true, "let": true, "set": true,
+	}
+)
+
+// extractMethodName returns the name of the method declared in a header line,
+// e.g. "LoadCustomers" for "Private Sub LoadCustomers(ByVal id As Long)".
+func extractMethodName(header string) string {
+	for _, match := range methodNameRegex.FindAllStringSubmatch(header, -1) {
+		if !methodKeywords[strings.ToLower(match[1])] {
+			return match[1]
+		}
+	}
+	return strings.TrimSpace(header)
+}
+
+func normalizeHeader(header string) string {
+	return strings.Join(strings.Fields(header), " ")
+}
+
+// similarity returns a value between 0 and 1 based on the Levenshtein
+// distance between both strings.
+func similarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// headerScore rates how likely newHeader is the same method as oldHeader.
+// Headers declaring the same method name always score above the others.
+func headerScore(oldHeader string, newHeader string) float64 {
+	score := similarity(normalizeHeader(oldHeader), normalizeHeader(newHeader))
+	if strings.EqualFold(extractMethodName(oldHeader), extractMethodName(newHeader)) {
+		return 0.5 + score/2
+	}
+	return score / 2
+}
+
+// findReanchorProposals looks for stored fields whose method header no longer
+// exists in its file and proposes the most similar header not reviewed yet.
+func findReanchorProposals() []reanchorProposal {
+	userFieldsMutex.Lock()
+	orphaned := make(map[string][]string) // filename -> headers
+	used := make(map[string]bool)         // filename<>header
+	for _, userField := range userFields {
+		key := userField.Filename + `<>` + userField.Method
+		if used[key] {
+			continue
+		}
+		used[key] = true
+		data, ok := filesData[getFilename(userField.Filename)]
+		if ok && data.getMethodHash(userField.Method) == "" {
+			orphaned[userField.Filename] = append(orphaned[userField.Filename], userField.Method)
+		}
+	}
+	userFieldsMutex.Unlock()
+
+	proposals := make([]reanchorProposal, 0)
+	for filename, headers := range orphaned {
+		data := filesData[getFilename(filename)]
+		for _, oldHeader := range headers {
+			best := reanchorProposal{}
+			for _, line := range data.Methods {
+				newHeader := data.Content[line]
+				if used[filename+`<>`+newHeader] {
+					continue // Ya tiene datos propios
+				}
+				if isReanchorDiscarded(getFilename(filename), oldHeader, newHeader) {
+					continue
+				}
+				if score := headerScore(oldHeader, newHeader); score > best.Score {
+					best = reanchorProposal{filename, oldHeader, newHeader, score}
+				}
+			}
+			if best.Score >= reanchorMinScore {
+				used[filename+`<>`+best.NewMethod] = true
+				proposals = append(proposals, best)
+			}
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Filename != proposals[j].Filename {
+			return proposals[i].Filename < proposals[j].Filename
+		}
+		return proposals[i].OldMethod < proposals[j].OldMethod
+	})
+
+	return proposals
+}
+
+func loadReanchorProposals() {
+	discarded := make([]reanchorDiscard, 0)
+	data, err := os.ReadFile(path.Join(pathProject, reanchorFilename))
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(logOutput, "Error reading discarded re-anchors: %v\n", err)
+	} else if err == nil {
+		if err := json.Unmarshal(data, &discarded); err != nil {
+			fmt.Fprintf(logOutput, "Error decoding discarded re-anchors: %v\n", err)
+		}
+	}
+
+	reanchorProposalsMutex.Lock()
+	reanchorDiscarded = discarded
+	reanchorProposalsMutex.Unlock()
+
+	proposals := findReanchorProposals()
+
+	reanchorProposalsMutex.Lock()
+	reanchorProposals = proposals
+	reanchorProposalsMutex.Unlock()
+
+	if len(proposals) > 0 {
+		fmt.Fprintf(logOutput, "Warning: %d method(s) changed their header, confirm them at /reanchor\n", len(proposals))
+	}
+}
+
+func getReanchorProposals() []reanchorProposal {
+	reanchorProposalsMutex.Lock()
+	defer reanchorProposalsMutex.Unlock()
+
+	return append([]reanchorProposal{}, reanchorProposals...)
+}
+
+func isReanchorDiscarded(file string, oldMethod string, newMethod string) bool {
+	reanchorProposalsMutex.Lock()
+	defer reanchorProposalsMutex.Unlock()
+
+	for _, discard := range reanchorDiscarded {
+		if discard == (reanchorDiscard{file, oldMethod, newMethod}) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveReanchorDiscarded requires reanchorProposalsMutex to be held.
+func saveReanchorDiscarded() error {
+	data, err := json.MarshalIndent(reanchorDiscarded, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path.Join(pathProject, reanchorFilename), append(data, '\n'))
+}
+
+// resolveReanchorProposal removes a proposal. When confirmed its fields and
+// links move to the new method header; when discarded it is saved so it is not
+// proposed again.
+func resolveReanchorProposal(filename string, oldMethod string, confirm bool) bool {
+	reanchorProposalsMutex.Lock()
+	found := false
+	var proposal reanchorProposal
+	for i := range reanchorProposals {
+		if reanchorProposals[i].Filename == filename && reanchorProposals[i].OldMethod == oldMethod {
+			proposal, found = reanchorProposals[i], true
+			reanchorProposals = append(reanchorProposals[:i], reanchorProposals[i+1:]...)
+			break
+		}
+	}
+	if found && !confirm {
+		reanchorDiscarded = append(reanchorDiscarded, reanchorDiscard{getFilename(filename), oldMethod, proposal.NewMethod})
+		if err := saveReanchorDiscarded(); err != nil {
+			fmt.Fprintf(logOutput, "Error saving discarded re-anchors: %v\n", err)
+		}
+	}
+	reanchorProposalsMutex.Unlock()
+
+	if !found || !confirm {
+		return found
+	}
+
+	// El hash guardado es del encabezado anterior
+	renameUserMethod(filename, oldMethod, proposal.NewMethod)
+	setUserMethodHash(filename, proposal.NewMethod, getMethodHash(filename, proposal.NewMethod))
+	markChanged()
+
+	if renameLinkedMethod(getFilename(filename), oldMethod, proposal.NewMethod) > 0 {
+		saveMappings()
+	}
+	return true
+}
+
+func reanchorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		confirm := r.Form.Get("action") == "confirm"
+		if !resolveReanchorProposal(r.Form.Get("filename"), r.Form.Get("method"), confirm) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, "/reanchor", http.StatusSeeOther)
+		return
+	}
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+	fmt.Fprintf(w, "<h3>🔗 Changed method headers</h3>")
+
+	proposals := getReanchorProposals()
+	if len(proposals) == 0 {
+		fmt.Fprintf(w, `<p>No pending changes.</p>`)
+	}
+	for _, proposal := range proposals {
+		fmt.Fprint(w, `<div class="file-section">`)
+		fmt.Fprint(w, `<h4>📄 `+parseEscapeHTML(getFilename(proposal.Filename))+`</h4>`)
+		fmt.Fprint(w, `<div class="fields">`)
+		fmt.Fprint(w, `<div class="method old">`+parseEscapeHTML(proposal.OldMethod)+`</div>`)
+		fmt.Fprint(w, `<div class="method">`+parseEscapeHTML(proposal.NewMethod)+`</div>`)
+		fmt.Fprintf(w, `<p>Similarity: %.0f%%</p>`, proposal.Score*100)
+		fmt.Fprint(w, `<form method="post" action="/reanchor" class="actions">`)
+		fmt.Fprint(w, `<input type="hidden" name="filename" value="`+parseEscapeHTML(proposal.Filename)+`">`)
+		fmt.Fprint(w, `<input type="hidden" name="method" value="`+parseEscapeHTML(proposal.OldMethod)+`">`)
+		fmt.Fprint(w, `<button type="submit" name="action" value="confirm">✔️ Confirm</button> `)
+		fmt.Fprint(w, `<button type="submit" name="action" value="discard">✖️ Discard</button>`)
+		fmt.Fprint(w, `</form></div></div>`)
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
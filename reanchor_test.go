@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestExtractMethodName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func main() {", "main"},
+		{"func (s *Server) Start(port int) error {", "Start"},
+		{"Private Sub LoadCustomers(ByVal id As Long)", "LoadCustomers"},
+		{"Public Function GetName() As String", "GetName"},
+		{"Public Property Get Name() As String", "Name"},
+		{"no method here", "no method here"},
+	}
+
+	for _, test := range tests {
+		result := extractMethodName(test.input)
+		if result != test.expected {
+			t.Errorf("extractMethodName(%s) = %s; expected %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "", 0},
+		{"abc", "xyz", 0},
+		{"abcd", "abce", 0.75},
+	}
+
+	for _, test := range tests {
+		result := similarity(test.a, test.b)
+		if result != test.expected {
+			t.Errorf("similarity(%s, %s) = %v; expected %v", test.a, test.b, result, test.expected)
+		}
+	}
+}
+
+func TestFindReanchorProposals(t *testing.T) {
+	// Setup
+	pathProject = t.TempDir()
+	file := pathProject + "/Form1.frm"
+	filesData = map[string]fileData{
+		"/Form1.frm": {
+			Filename: file,
+			Content: []string{
+				"Private Sub LoadCustomers(ByVal customerId As Long)",
+				"End Sub",
+				"Private Sub SaveCustomers()",
+				"End Sub",
+				"Private Sub Unrelated()",
+				"End Sub",
+			},
+			Methods: []int{0, 2, 4},
+		},
+	}
+	setUserFields([]fieldsData{
+		{Filename: file, Method: "Private Sub LoadCustomers(ByVal id As Long)", Field: "Checked", Value: "1", Hash: "old"},
+		{Filename: file, Method: "Private Sub LoadCustomers(ByVal id As Long)", Field: "Notes", Value: "ok"},
+		{Filename: file, Method: "Private Sub SaveCustomers()", Field: "Checked", Value: "1"},
+		{Filename: file, Method: "Private Sub Removed(x As Integer, y As Integer)", Field: "Checked", Value: "1"},
+		{Filename: pathProject + "/Gone.frm", Method: "Private Sub Gone()", Field: "Checked", Value: "1"},
+	})
+
+	proposals := findReanchorProposals()
+	if len(proposals) != 1 {
+		t.Fatalf("Expected 1 proposal, got %d: %+v", len(proposals), proposals)
+	}
+	if proposals[0].NewMethod != "Private Sub LoadCustomers(ByVal customerId As Long)" {
+		t.Errorf("Unexpected proposal: %+v", proposals[0])
+	}
+
+	reanchorProposals = proposals
+	methodLinks = []methodLink{{"/Form1.frm", "Private Sub LoadCustomers(ByVal id As Long)", "Customers.cs", "void LoadCustomers(long id)"}}
+
+	// Discarding keeps the data untouched and is not proposed again
+	if !resolveReanchorProposal(file, "Private Sub LoadCustomers(ByVal id As Long)", false) {
+		t.Error("Expected proposal to be resolved")
+	}
+	if getUserValue(file, "Private Sub LoadCustomers(ByVal id As Long)", "Notes") != "ok" {
+		t.Error("Discarded proposal should not move the data")
+	}
+	loadReanchorProposals()
+	if len(getReanchorProposals()) != 0 {
+		t.Errorf("Discarded proposal should not be proposed after reloading, got %+v", getReanchorProposals())
+	}
+
+	// Confirming moves the data and the links to the new header
+	reanchorProposals = proposals
+	if !resolveReanchorProposal(file, "Private Sub LoadCustomers(ByVal id As Long)", true) {
+		t.Error("Expected proposal to be resolved")
+	}
+	if getUserValue(file, "Private Sub LoadCustomers(ByVal customerId As Long)", "Notes") != "ok" {
+		t.Error("Confirmed proposal should move the data")
+	}
+	if getUserValue(file, "Private Sub LoadCustomers(ByVal id As Long)", "Notes") != "" {
+		t.Error("Old header should not keep the data")
+	}
+	if methodNeedsReview(file, "Private Sub LoadCustomers(ByVal customerId As Long)") {
+		t.Error("Confirmed proposal should not flag the method for review")
+	}
+	if moved, _ := getUserField(file, "Private Sub LoadCustomers(ByVal customerId As Long)", "Checked"); moved.Hash != getMethodHash(file, moved.Method) {
+		t.Error("Confirmed proposal should store the hash of the new header")
+	}
+	if links := getLinkedMethods("/Form1.frm", "Private Sub LoadCustomers(ByVal customerId As Long)"); len(links) != 1 {
+		t.Errorf("Confirmed proposal should move the links, got %+v", getMethodLinks())
+	}
+	if len(getReanchorProposals()) != 0 {
+		t.Error("Expected no pending proposals")
+	}
+}
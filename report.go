@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportRow is a method of the report, with the value of every selected
+// field. Methods without values are included with empty values.
+type reportRow struct {
+	File   string
+	Line   int
+	Method string
+	Values []string
+}
+
+var reportFormats = map[string]struct {
+	ContentType string
+	Extension   string
+	Write       func(w io.Writer, fields []UserField, rows []reportRow) error
+}{
+	"csv":  {"text/csv; charset=utf-8", ".csv", writeReportCSV},
+	"md":   {"text/markdown; charset=utf-8", ".md", writeReportMarkdown},
+	"html": {"text/html; charset=utf-8", ".html", writeReportHtml},
+}
+
+// parseReportColumns returns the fields named in a comma separated list, or
+// every field when the list is empty.
+func parseReportColumns(value string) ([]UserField, error) {
+	if strings.TrimSpace(value) == "" {
+		return configProject.UserFields, nil
+	}
+
+	fields := make([]UserField, 0)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		field, ok := getUserFieldConfig(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func getReportRows(fields []UserField) []reportRow {
+	rows := make([]reportRow, 0)
+	for _, filepath := range projectFiles {
+		data := filesData[getFilename(filepath)]
+		for _, line := range data.Methods {
+			method := data.Content[line]
+			row := reportRow{
+				File:   getFilename(data.Filename),
+				Line:   line + 1,
+				Method: strings.TrimSpace(method),
+				Values: make([]string, 0, len(fields)),
+			}
+			for _, field := range fields {
+				row.Values = append(row.Values, formatReportValue(field, getUserValue(data.Filename, method, field.Name)))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func formatReportValue(field UserField, value string) string {
+	if field.Type == EnumBoolean {
+		if isFieldDone(field, value) {
+			return "yes"
+		}
+		return "no"
+	}
+	return value
+}
+
+func getReportTitle() string {
+	if configProject.ProjectName == "" {
+		return "Review report"
+	}
+	return configProject.ProjectName + " review report"
+}
+
+func writeReportCSV(w io.Writer, fields []UserField, rows []reportRow) error {
+	writer := csv.NewWriter(w)
+	header := []string{"File", "Line", "Method"}
+	for _, field := range fields {
+		header = append(header, escapeCSVCell(field.Name))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{escapeCSVCell(row.File), strconv.Itoa(row.Line), escapeCSVCell(row.Method)}
+		for _, value := range row.Values {
+			record = append(record, escapeCSVCell(value))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeCSVCell prefixes with "'" the values that a spreadsheet would run as
+// a formula, like notes starting with "=" or "-".
+func escapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// escapeMarkdownCell keeps a value inside its table cell.
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+func writeReportMarkdown(w io.Writer, fields []UserField, rows []reportRow) error {
+	var md strings.Builder
+	md.WriteString("# " + getReportTitle() + "\n\n")
+	md.WriteString(fmt.Sprintf("Generated on %s, %d method(s).\n\n", time.Now().Format("2006-01-02 15:04"), len(rows)))
+
+	md.WriteString("| File | Line | Method |")
+	separator := "| --- | ---: | --- |"
+	for _, field := range fields {
+		md.WriteString(" " + escapeMarkdownCell(field.Name) + " |")
+		separator += " --- |"
+	}
+	md.WriteString("\n" + separator + "\n")
+
+	for _, row := range rows {
+		md.WriteString("| " + escapeMarkdownCell(row.File) + " | " + strconv.Itoa(row.Line) + " | " + escapeMarkdownCell(row.Method) + " |")
+		for _, value := range row.Values {
+			md.WriteString(" " + escapeMarkdownCell(value) + " |")
+		}
+		md.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, md.String())
+	return err
+}
+
+// writeReportHtml writes a standalone page meant to be printed, with a table
+// per file.
+func writeReportHtml(w io.Writer, fields []UserField, rows []reportRow) error {
+	title := parseEscapeHTML(getReportTitle())
+
+	var html strings.Builder
+	html.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>` + title + `</title><style>
+	body { font-family: sans-serif; font-size: 12px; margin: 20px; }
+	table { border-collapse: collapse; width: 100%; margin-bottom: 20px; }
+	th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; vertical-align: top; }
+	th { background: #eee; }
+	td.line { text-align: right; width: 50px; }
+	td.value { white-space: pre-wrap; }
+	h2 { font-size: 14px; margin-top: 20px; }
+	@media print { h2 { page-break-after: avoid; } tr { page-break-inside: avoid; } }
+	</style></head><body>`)
+	html.WriteString(`<h1>` + title + `</h1>`)
+	html.WriteString(fmt.Sprintf(`<p>Generated on %s, %d method(s).</p>`, time.Now().Format("2006-01-02 15:04"), len(rows)))
+
+	header := `<tr><th>Line</th><th>Method</th>`
+	for _, field := range fields {
+		header += `<th>` + parseEscapeHTML(field.Name) + `</th>`
+	}
+	header += `</tr>`
+
+	file := ""
+	for _, row := range rows {
+		if row.File != file {
+			if file != "" {
+				html.WriteString(`</table>`)
+			}
+			file = row.File
+			html.WriteString(`<h2>` + parseEscapeHTML(file) + `</h2><table>` + header)
+		}
+		html.WriteString(`<tr><td class="line">` + strconv.Itoa(row.Line) + `</td><td><code>` + parseEscapeHTML(row.Method) + `</code></td>`)
+		for _, value := range row.Values {
+			html.WriteString(`<td class="value">` + parseEscapeHTML(value) + `</td>`)
+		}
+		html.WriteString(`</tr>`)
+	}
+	if file != "" {
+		html.WriteString(`</table>`)
+	}
+	html.WriteString(`</body></html>`)
+
+	_, err := io.WriteString(w, html.String())
+	return err
+}
+
+// getReportFilename is the name suggested when the report is downloaded.
+func getReportFilename(extension string) string {
+	name := "review-report"
+	if configProject.ProjectName != "" {
+		name = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`\/:*?"<>| `, r) {
+				return '-'
+			}
+			return r
+		}, configProject.ProjectName) + "-review"
+	}
+	return name + extension
+}
+
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("format")
+	if name == "" {
+		name = "html"
+	}
+	format, ok := reportFormats[name]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "Invalid format: "+parseEscapeHTML(name))
+		return
+	}
+
+	fields, err := parseReportColumns(query.Get("columns"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, parseEscapeHTML(err.Error()))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := format.Write(&buf, fields, getReportRows(fields)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, parseEscapeHTML(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType)
+	if name != "html" || query.Get("download") != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+getReportFilename(format.Extension)+`"`)
+	}
+	w.Write(buf.Bytes())
+}
+
+// getReportLinksHtml links the downloads of the report for the dashboard.
+func getReportLinksHtml() string {
+	return `<div class="actions">⬇️ Report: <a href="/report?format=csv">CSV</a> · <a href="/report?format=md">Markdown</a> · <a href="/report?format=html" target="_blank">Printable</a></div>`
+}
+
+func reportCommand(args []string) int {
+	var format string
+	var columns string
+	var outFile string
+
+	flags := newCommandFlags("report")
+	flags.StringVar(&format, "format", "csv", "output format: csv, md or html")
+	flags.StringVar(&columns, "columns", "", "comma separated fields to include, all by default")
+	flags.StringVar(&outFile, "out", "", "output file, stdout by default")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	writer, ok := reportFormats[format]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Invalid format:", format)
+		return 2
+	}
+
+	if !loadCommandProject() {
+		return 1
+	}
+
+	fields, err := parseReportColumns(columns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid columns: %v\n", err)
+		return 2
+	}
+
+	var buf bytes.Buffer
+	rows := getReportRows(fields)
+	if err := writer.Write(&buf, fields, rows); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		return 1
+	}
+
+	if outFile == "" {
+		os.Stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(outFile, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Report written to %s: %d method(s)\n", outFile, len(rows))
+	return 0
+}
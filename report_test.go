@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetReportRows(t *testing.T) {
+	setupFilterProject()
+
+	fields, err := parseReportColumns("Checked, Notes")
+	if err != nil {
+		t.Fatalf("parseReportColumns failed: %v", err)
+	}
+
+	rows := getReportRows(fields)
+	if len(rows) != 3 {
+		t.Fatalf("Expected every method in the report, got %d rows", len(rows))
+	}
+	expected := []reportRow{
+		{"main.go", 2, "func a() {", []string{"yes", ""}},
+		{"main.go", 4, "func b() {", []string{"no", "check nulls"}},
+		{"main.go", 6, "func c() {", []string{"no", ""}},
+	}
+	for i, row := range rows {
+		if row.File != expected[i].File || row.Line != expected[i].Line || row.Method != expected[i].Method ||
+			strings.Join(row.Values, ",") != strings.Join(expected[i].Values, ",") {
+			t.Errorf("row %d = %+v; expected %+v", i, row, expected[i])
+		}
+	}
+
+	if all, _ := parseReportColumns(""); len(all) != 3 {
+		t.Errorf("Expected every field without columns, got %d", len(all))
+	}
+	if _, err := parseReportColumns("Checked,Unknown"); err == nil {
+		t.Error("parseReportColumns should fail on an unknown field")
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	fields := []UserField{{Name: "Checked", Type: EnumBoolean}, {Name: "Notes", Type: EnumTextBox}}
+	rows := []reportRow{{"main.go", 2, "func a(x, y int) {", []string{"yes", "a | b\nsecond \"line\""}}}
+
+	tests := []struct {
+		write    func(w *bytes.Buffer) error
+		expected string
+	}{
+		{func(w *bytes.Buffer) error { return writeReportCSV(w, fields, rows) },
+			"File,Line,Method,Checked,Notes\nmain.go,2,\"func a(x, y int) {\",yes,\"a | b\nsecond \"\"line\"\"\"\n"},
+		{func(w *bytes.Buffer) error { return writeReportMarkdown(w, fields, rows) },
+			"| File | Line | Method | Checked | Notes |\n| --- | ---: | --- | --- | --- |\n| main.go | 2 | func a(x, y int) { | yes | a \\| b<br>second \"line\" |\n"},
+		{func(w *bytes.Buffer) error { return writeReportHtml(w, fields, rows) },
+			`<h2>main.go</h2><table><tr><th>Line</th><th>Method</th><th>Checked</th><th>Notes</th></tr><tr><td class="line">2</td><td><code>func a(x, y int) {</code></td><td class="value">yes</td><td class="value">a | b` + "\n" + `second &#34;line&#34;</td></tr></table>`},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := test.write(&buf); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), test.expected) {
+			t.Errorf("Report should contain:\n%s\ngot:\n%s", test.expected, buf.String())
+		}
+	}
+}
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1", "'+1"},
+		{"- check nulls", "'- check nulls"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"a = b", "a = b"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if result := escapeCSVCell(test.value); result != test.expected {
+			t.Errorf("escapeCSVCell(%q) = %q; expected %q", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestReportHandler(t *testing.T) {
+	setupFilterProject()
+	configProject.ProjectName = "Legacy App"
+
+	recorder := httptest.NewRecorder()
+	reportHandler(recorder, httptest.NewRequest(http.MethodGet, "/report?format=csv&columns=Notes", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Disposition") != `attachment; filename="Legacy-App-review.csv"` {
+		t.Errorf("Unexpected response %d %v", recorder.Code, recorder.Header())
+	}
+	if body := recorder.Body.String(); !strings.HasPrefix(body, "File,Line,Method,Notes\n") || strings.Count(body, "\n") != 4 {
+		t.Errorf("Unexpected CSV:\n%s", body)
+	}
+
+	recorder = httptest.NewRecorder()
+	reportHandler(recorder, httptest.NewRequest(http.MethodGet, "/report", nil))
+	if recorder.Header().Get("Content-Disposition") != "" || !strings.Contains(recorder.Body.String(), "<h1>Legacy App review report</h1>") {
+		t.Errorf("The HTML report should be shown inline")
+	}
+
+	for _, url := range []string{"/report?format=xls", "/report?columns=Unknown"} {
+		recorder = httptest.NewRecorder()
+		reportHandler(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s returned %d", url, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	reviewerCookie    = "zoomer_reviewer"
+	reviewerHeader    = "X-Zoomer-Reviewer"
+	maxReviewerLength = 64
+)
+
+// defaultReviewer is set with --user when a single person uses Zoomer. It is
+// used for every change and the name prompt is not shown.
+var defaultReviewer string
+
+// getRequestReviewer identifies who made a change from the web: the --user
+// flag, the header sent by scripts, the name cookie set by the page or, as a
+// last resort, the IP address.
+func getRequestReviewer(r *http.Request) string {
+	if defaultReviewer != "" {
+		return defaultReviewer
+	}
+	if reviewer := cleanReviewer(r.Header.Get(reviewerHeader)); reviewer != "" {
+		return reviewer
+	}
+	if cookie, err := r.Cookie(reviewerCookie); err == nil {
+		if value, err := url.PathUnescape(cookie.Value); err == nil {
+			if reviewer := cleanReviewer(value); reviewer != "" {
+				return reviewer
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func cleanReviewer(name string) string {
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > maxReviewerLength {
+		name = string(runes[:maxReviewerLength])
+	}
+	return name
+}
+
+// getAttributionText describes the last change of a field, like
+// "checked by Ana, 2 days ago".
+func getAttributionText(field UserField, userField fieldsData, now time.Time) string {
+	if userField.UpdatedAt == nil {
+		return ""
+	}
+
+	action := "edited"
+	if field.Type == EnumBoolean {
+		action = "checked"
+		if userField.Value != "1" {
+			action = "unchecked"
+		}
+	} else if userField.Value == "" {
+		action = "cleared"
+	}
+
+	text := action
+	if userField.Reviewer != "" {
+		text += " by " + userField.Reviewer + ","
+	}
+	return text + " " + formatTimeAgo(*userField.UpdatedAt, now)
+}
+
+func formatTimeAgo(t time.Time, now time.Time) string {
+	elapsed := now.Sub(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return pluralAgo(int(elapsed/time.Minute), "minute")
+	case elapsed < 24*time.Hour:
+		return pluralAgo(int(elapsed/time.Hour), "hour")
+	case elapsed < 30*24*time.Hour:
+		return pluralAgo(int(elapsed/(24*time.Hour)), "day")
+	}
+	return "on " + t.Local().Format("2006-01-02")
+}
+
+func pluralAgo(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s ago", unit)
+	}
+	return fmt.Sprintf("%d %ss ago", n, unit)
+}
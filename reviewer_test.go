@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetRequestReviewer(t *testing.T) {
+	defer func() { defaultReviewer = "" }()
+
+	tests := []struct {
+		user     string
+		header   string
+		cookie   string
+		expected string
+	}{
+		{"", "", "", "192.168.1.20"},
+		{"", "", "Ana%20P%C3%A9rez", "Ana Pérez"},
+		{"", "", "%20%20", "192.168.1.20"},
+		{"", "ci-bot", "Ana", "ci-bot"},
+		{"Luis", "ci-bot", "Ana", "Luis"},
+	}
+
+	for _, test := range tests {
+		defaultReviewer = test.user
+		request := httptest.NewRequest(http.MethodPost, "/save", nil)
+		request.RemoteAddr = "192.168.1.20:51234"
+		if test.header != "" {
+			request.Header.Set(reviewerHeader, test.header)
+		}
+		if test.cookie != "" {
+			request.AddCookie(&http.Cookie{Name: reviewerCookie, Value: test.cookie})
+		}
+		if reviewer := getRequestReviewer(request); reviewer != test.expected {
+			t.Errorf("getRequestReviewer(user=%q, header=%q, cookie=%q) = %q; expected %q",
+				test.user, test.header, test.cookie, reviewer, test.expected)
+		}
+	}
+}
+
+func TestGetAttributionText(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+	checked := UserField{Name: "Checked", Type: EnumBoolean}
+	notes := UserField{Name: "Notes", Type: EnumTextBox}
+
+	tests := []struct {
+		field     UserField
+		userField fieldsData
+		expected  string
+	}{
+		{checked, fieldsData{Value: "1"}, ""},
+		{checked, fieldsData{Value: "1", Reviewer: "Ana", UpdatedAt: ago(48 * time.Hour)}, "checked by Ana, 2 days ago"},
+		{checked, fieldsData{Value: "0", Reviewer: "Ana", UpdatedAt: ago(time.Hour)}, "unchecked by Ana, 1 hour ago"},
+		{notes, fieldsData{Value: "todo", UpdatedAt: ago(5 * time.Minute)}, "edited 5 minutes ago"},
+		{notes, fieldsData{Value: "", Reviewer: "Luis", UpdatedAt: ago(time.Second)}, "cleared by Luis, just now"},
+		{notes, fieldsData{Value: "x", Reviewer: "Luis", UpdatedAt: ago(60 * 24 * time.Hour)}, "edited by Luis, on 2024-03-11"},
+	}
+
+	for _, test := range tests {
+		if text := getAttributionText(test.field, test.userField, now); text != test.expected {
+			t.Errorf("getAttributionText(%+v) = %q; expected %q", test.userField, text, test.expected)
+		}
+	}
+}
+
+func TestSaveHandlerAttribution(t *testing.T) {
+	setupHistoryProject(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/save", strings.NewReader("name="+pathProject+"main.go<>func a() {<>Checked&value=1"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: reviewerCookie, Value: "Ana"})
+	saveHandler(httptest.NewRecorder(), request)
+
+	userField, ok := getUserField(pathProject+"main.go", "func a() {", "Checked")
+	if !ok || userField.Reviewer != "Ana" || userField.UpdatedAt == nil {
+		t.Fatalf("Expected the value to be attributed to Ana, got %+v", userField)
+	}
+
+	content := filesData["main.go"].getContentHTMLWithFields()
+	if !strings.Contains(content, `<div class="attribution">checked by Ana, just now</div>`) {
+		t.Errorf("Expected attribution next to the field, got %s", content)
+	}
+}
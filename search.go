@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	searchContextLines = 2
+	maxSearchHits      = 500
+)
+
+type searchHit struct {
+	File    string   `json:"file"`
+	Line    int      `json:"line"` // Desde 1
+	Method  string   `json:"method,omitempty"`
+	Anchor  string   `json:"anchor,omitempty"`
+	Context []string `json:"context"`
+	Start   int      `json:"start"` // Línea de la primera línea de contexto
+}
+
+type searchResult struct {
+	Query     string      `json:"query"`
+	Hits      []searchHit `json:"hits"`
+	Truncated bool        `json:"truncated"`
+}
+
+// compileSearch returns the expression of a search. Plain text searches are
+// escaped so both kinds share the same matching code.
+func compileSearch(query string, isRegex bool, matchCase bool) (*regexp.Regexp, error) {
+	if !isRegex {
+		query = regexp.QuoteMeta(query)
+	}
+	if !matchCase {
+		query = "(?i)" + query
+	}
+	return regexp.Compile(query)
+}
+
+// getMethodAnchor returns the id of the section of the method whose header is
+// at the given line (from 0).
+func getMethodAnchor(line int) string {
+	return fmt.Sprintf("method-%d", line+1)
+}
+
+// getMethodAtLine returns the line of the header of the method that contains
+// a line, or false if the line is before the first method.
+func (f fileData) getMethodAtLine(line int) (int, bool) {
+	header, found := 0, false
+	for _, method := range f.Methods {
+		if method > line {
+			break
+		}
+		header, found = method, true
+	}
+	return header, found
+}
+
+func searchProject(expr *regexp.Regexp) searchResult {
+	result := searchResult{Query: expr.String(), Hits: make([]searchHit, 0)}
+	for _, filepath := range projectFiles {
+		filename := getFilename(filepath)
+		data := filesData[filename]
+		for i, line := range data.Content {
+			if !expr.MatchString(line) {
+				continue
+			}
+			if len(result.Hits) >= maxSearchHits {
+				result.Truncated = true
+				return result
+			}
+
+			start := i - searchContextLines
+			if start < 0 {
+				start = 0
+			}
+			end := i + searchContextLines + 1
+			if end > len(data.Content) {
+				end = len(data.Content)
+			}
+
+			hit := searchHit{File: filename, Line: i + 1, Context: data.Content[start:end], Start: start + 1}
+			if header, ok := data.getMethodAtLine(i); ok {
+				hit.Method = data.Content[header]
+				hit.Anchor = getMethodAnchor(header)
+			}
+			result.Hits = append(result.Hits, hit)
+		}
+	}
+	return result
+}
+
+// parseSearchRequest reads the q, regex and case parameters of a search.
+func parseSearchRequest(r *http.Request) (string, *regexp.Regexp, error) {
+	query := r.URL.Query()
+	text := query.Get("q")
+	if text == "" {
+		return "", nil, nil
+	}
+	expr, err := compileSearch(text, query.Get("regex") == "1", query.Get("case") == "1")
+	return text, expr, err
+}
+
+func apiSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	text, expr, err := parseSearchRequest(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid regex: "+err.Error())
+		return
+	}
+	if expr == nil {
+		writeJSONError(w, http.StatusBadRequest, "missing query (q)")
+		return
+	}
+
+	result := searchProject(expr)
+	result.Query = text
+	writeJSON(w, http.StatusOK, result)
+}
+
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	text, expr, err := parseSearchRequest(r)
+	query := r.URL.Query()
+
+	headerHtml(w)
+	fmt.Fprintf(w, `<div class="content">`)
+	fmt.Fprint(w, "<h3>🔍 Search</h3>")
+
+	fmt.Fprint(w, `<form method="get" action="/search" class="actions">`)
+	fmt.Fprint(w, `<input type="search" name="q" value="`+parseEscapeHTML(text)+`" placeholder="Text or regex" autofocus> `)
+	fmt.Fprint(w, `<label><input type="checkbox" name="regex" value="1"`+getCheckedAttr(query.Get("regex") == "1")+`> Regex</label> `)
+	fmt.Fprint(w, `<label><input type="checkbox" name="case" value="1"`+getCheckedAttr(query.Get("case") == "1")+`> Match case</label> `)
+	fmt.Fprint(w, `<button type="submit">🔍 Search</button></form>`)
+
+	if err != nil {
+		fmt.Fprint(w, `<p class="not-migrated">Invalid regex: `+parseEscapeHTML(err.Error())+`</p>`)
+	} else if expr != nil {
+		showSearchResultHtml(w, searchProject(expr), expr)
+	}
+
+	fmt.Fprintf(w, `</div></div>`)
+	footerHtml(w)
+}
+
+func showSearchResultHtml(w http.ResponseWriter, result searchResult, expr *regexp.Regexp) {
+	if len(result.Hits) == 0 {
+		fmt.Fprint(w, `<p>No results.</p>`)
+		return
+	}
+
+	fmt.Fprintf(w, `<p>%d result(s)`, len(result.Hits))
+	if result.Truncated {
+		fmt.Fprintf(w, `, only the first %d are shown`, maxSearchHits)
+	}
+	fmt.Fprint(w, `</p>`)
+
+	for _, hit := range result.Hits {
+		link := serverPage.FileLink(hit.File)
+		if hit.Anchor != "" {
+			link += "#" + hit.Anchor
+		}
+
+		fmt.Fprint(w, `<div class="file-section search-hit">`)
+		fmt.Fprintf(w, `<div class="target-file"><a href="%s">📄 %s:%d</a>`, parseEscapeHTML(link), parseEscapeHTML(hit.File), hit.Line)
+		if hit.Method != "" {
+			fmt.Fprint(w, ` — `+parseEscapeHTML(hit.Method))
+		}
+		fmt.Fprint(w, `</div><pre class="snippet">`)
+		for i, line := range hit.Context {
+			number := hit.Start + i
+			if number == hit.Line {
+				fmt.Fprintf(w, `<span class="hit">%5d  %s</span>`+"\n", number, getMarkedHtml(line, expr))
+			} else {
+				fmt.Fprintf(w, "%5d  %s\n", number, parseEscapeHTML(line))
+			}
+		}
+		fmt.Fprint(w, `</pre></div>`)
+	}
+}
+
+// getMarkedHtml escapes a line wrapping the matches of the search in <mark>.
+func getMarkedHtml(line string, expr *regexp.Regexp) string {
+	var html strings.Builder
+	last := 0
+	for _, match := range expr.FindAllStringIndex(line, -1) {
+		html.WriteString(parseEscapeHTML(line[last:match[0]]))
+		html.WriteString(`<mark>` + parseEscapeHTML(line[match[0]:match[1]]) + `</mark>`)
+		last = match[1]
+	}
+	html.WriteString(parseEscapeHTML(line[last:]))
+	return html.String()
+}
+
+func getCheckedAttr(checked bool) string {
+	if checked {
+		return ` checked`
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setupSearchProject() {
+	setupTestProject("/test/project/", nil,
+		testFile{"main.go", []string{"package main", "var total = 0", "func add(x int) {", "\ttotal += x", "}", "func Reset() {", "\ttotal = 0", "}"}})
+}
+
+func TestSearchProject(t *testing.T) {
+	setupSearchProject()
+
+	tests := []struct {
+		query     string
+		isRegex   bool
+		matchCase bool
+		lines     []int
+		methods   []string
+	}{
+		{"total", false, false, []int{2, 4, 7}, []string{"", "func add(x int) {", "func Reset() {"}},
+		{"RESET", false, false, []int{6}, []string{"func Reset() {"}},
+		{"RESET", false, true, nil, nil},
+		{`total \+?= 0`, true, false, []int{2, 7}, []string{"", "func Reset() {"}},
+		{"x int)", false, false, []int{3}, []string{"func add(x int) {"}},
+	}
+
+	for _, test := range tests {
+		expr, err := compileSearch(test.query, test.isRegex, test.matchCase)
+		if err != nil {
+			t.Fatalf("compileSearch(%q) failed: %v", test.query, err)
+		}
+		result := searchProject(expr)
+		if len(result.Hits) != len(test.lines) {
+			t.Errorf("search %q returned %d hits; expected %d", test.query, len(result.Hits), len(test.lines))
+			continue
+		}
+		for i, hit := range result.Hits {
+			if hit.Line != test.lines[i] || hit.Method != test.methods[i] {
+				t.Errorf("search %q hit %d = %d %q; expected %d %q", test.query, i, hit.Line, hit.Method, test.lines[i], test.methods[i])
+			}
+		}
+	}
+
+	expr, _ := compileSearch("Reset", false, true)
+	hit := searchProject(expr).Hits[0]
+	if hit.Anchor != "method-6" || hit.Start != 4 || len(hit.Context) != 5 {
+		t.Errorf("Unexpected hit context: %+v", hit)
+	}
+
+	if _, err := compileSearch("(", true, false); err == nil {
+		t.Error("compileSearch should fail on an invalid regex")
+	}
+}
+
+func TestGetMarkedHtml(t *testing.T) {
+	expr, _ := compileSearch("a", false, false)
+	if html := getMarkedHtml("<a>A", expr); html != "&lt;<mark>a</mark>&gt;<mark>A</mark>" {
+		t.Errorf("getMarkedHtml = %s", html)
+	}
+}
+
+func TestSearchHandlers(t *testing.T) {
+	setupSearchProject()
+
+	recorder := httptest.NewRecorder()
+	searchHandler(recorder, httptest.NewRequest(http.MethodGet, "/search?q=reset", nil))
+	body := recorder.Body.String()
+	if !strings.Contains(body, `href="/file/main.go#method-6"`) || !strings.Contains(body, "<mark>Reset</mark>") {
+		t.Errorf("Search page should link to the method and mark the match:\n%s", body)
+	}
+
+	recorder = httptest.NewRecorder()
+	apiSearchHandler(recorder, httptest.NewRequest(http.MethodGet, apiPrefix+"/search?q=(&regex=1", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Invalid regex returned %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	apiSearchHandler(recorder, httptest.NewRequest(http.MethodGet, apiPrefix+"/search?q=total", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"anchor":"method-3"`) {
+		t.Errorf("Unexpected API response %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"html"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 func initServer() {
 	srv := http.Server{
 		Addr:         fmt.Sprint(":", listenPort),
@@ -17,32 +28,237 @@ func initServer() {
 	}
 
 	http.HandleFunc("/", handler)
+	http.HandleFunc("/file/", fileHandler)
 	http.HandleFunc("/save", saveHandler)
+	http.Handle("/assets/", assetsHandler())
+	http.HandleFunc("/reanchor", reanchorHandler)
+	http.HandleFunc("/orphans", orphansHandler)
+	http.HandleFunc("/conflicts", conflictsHandler)
+	http.HandleFunc("/migration", migrationHandler)
+	http.HandleFunc("/mappings", mappingsHandler)
+	http.HandleFunc("/dashboard", dashboardHandler)
+	http.HandleFunc("/report", reportHandler)
+	http.HandleFunc("/search", searchHandler)
+	http.HandleFunc("/review", reviewHandler)
+	http.HandleFunc(apiPrefix+"/files", apiFilesHandler)
+	http.HandleFunc(apiPrefix+"/methods", apiMethodsHandler)
+	http.HandleFunc(apiPrefix+"/values", apiValuesHandler)
+	http.HandleFunc(apiPrefix+"/search", apiSearchHandler)
+	http.HandleFunc(apiPrefix+"/history", apiHistoryHandler)
+
+	stopped := make(chan struct{})
+	go func() {
+		waitForShutdown(&srv)
+		close(stopped)
+	}()
+
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Server error: %v\n", err)
+		return
+	}
+	createServerLock()
+	defer os.Remove(path.Join(pathProject, serverLockFilename))
+
+	fmt.Fprintln(logOutput, "Server is listening on port", listenPort)
+	if err := srv.Serve(listener); err != http.ErrServerClosed {
+		fmt.Fprintf(logOutput, "Server error: %v\n", err)
+		return
+	}
+	<-stopped
+}
+
+// createServerLock writes the port of the server in the project, so the
+// commands that change the review data can tell that it is running.
+func createServerLock() {
+	if err := os.WriteFile(path.Join(pathProject, serverLockFilename), []byte(listenPort+"\n"), 0644); err != nil {
+		fmt.Fprintf(logOutput, "Error writing %s: %v\n", serverLockFilename, err)
+	}
+}
 
-	fmt.Println("Server is listening on port", listenPort)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		fmt.Printf("Server error: %v\n", err)
+// isServerRunning reports whether a server is serving the project. A lock
+// left by a server that was killed is ignored when nothing answers on its
+// port.
+func isServerRunning() bool {
+	data, err := os.ReadFile(path.Join(pathProject, serverLockFilename))
+	if err != nil {
+		return false
 	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strings.TrimSpace(string(data))), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// waitForShutdown stops the server on Ctrl+C or SIGTERM, letting the requests
+// in progress finish so their changes are included in the final save.
+func waitForShutdown(srv *http.Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	signal.Stop(signals) // Un segundo Ctrl+C termina sin esperar
+
+	fmt.Fprintln(logOutput, "Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Fprintf(logOutput, "Error shutting down server: %v\n", err)
+	}
+}
+
+// pageOptions changes how the pages are rendered, served by initServer or
+// written as a static site by the export command.
+type pageOptions struct {
+	Root     string // Prefijo de enlaces y recursos
+	Index    string
+	Current  string // Archivo mostrado en la página, si hay uno
+	ReadOnly bool
+	FileLink func(filename string) string
+}
+
+var serverPage = pageOptions{
+	Root:     "/",
+	Index:    "/",
+	FileLink: getFileURL,
+}
+
+// getFileURL returns the route of the page of a file: /file/<path>
+func getFileURL(filename string) string {
+	segments := strings.Split(strings.TrimPrefix(filename, "/"), "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return "/file/" + strings.Join(segments, "/")
+}
+
+// findFileData looks up a file by the path of its page. Files of a project
+// path without trailing separator start with "/", which the URL loses.
+func findFileData(name string) (fileData, bool) {
+	if data, ok := filesData[name]; ok {
+		return data, true
+	}
+	data, ok := filesData["/"+name]
+	return data, ok
 }
 
 func handler(w http.ResponseWriter, r *http.Request) {
-	headerHtml(w)
-	fmt.Fprintf(w, `<div class="content">`)
-	fmt.Fprintf(w, "<h3>📄 Project Files</h3>")
-	for _, filepath := range projectFiles {
-		showSourceHtml(w, filepath)
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	showFileIndexHtml(w, serverPage)
+}
+
+func fileHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := findFileData(strings.TrimPrefix(r.URL.Path, "/file/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	page := serverPage
+	page.Current = getFilename(data.Filename)
+	showFilePageHtml(w, data.Filename, page)
+}
+
+func showFilePageHtml(w io.Writer, filepath string, page pageOptions) {
+	writeHeaderHtml(w, page)
+	fmt.Fprint(w, `<div class="content">`)
+	showFileNavHtml(w, page)
+	showSourceHtml(w, filepath, page)
+	showFileNavHtml(w, page)
+	fmt.Fprint(w, `</div></div>`)
+	writeFooterHtml(w, page)
+}
+
+// showFileNavHtml writes the links to the previous and next files.
+func showFileNavHtml(w io.Writer, page pageOptions) {
+	var prev, next string
+	for i, filepath := range projectFiles {
+		if getFilename(filepath) != page.Current {
+			continue
+		}
+		if i > 0 {
+			prev = getFilename(projectFiles[i-1])
+		}
+		if i+1 < len(projectFiles) {
+			next = getFilename(projectFiles[i+1])
+		}
+		break
 	}
-	fmt.Fprintf(w, `</div></div>`)
-	footerHtml(w)
+
+	fmt.Fprint(w, `<div class="file-nav">`)
+	if prev != "" {
+		fmt.Fprint(w, `<a href="`+parseEscapeHTML(page.FileLink(prev))+`">⬅️ `+parseEscapeHTML(prev)+`</a>`)
+	} else {
+		fmt.Fprint(w, `<span></span>`)
+	}
+	fmt.Fprint(w, `<a href="`+parseEscapeHTML(page.Index)+`">📄 Index</a>`)
+	if next != "" {
+		fmt.Fprint(w, `<a href="`+parseEscapeHTML(page.FileLink(next))+`">`+parseEscapeHTML(next)+` ➡️</a>`)
+	} else {
+		fmt.Fprint(w, `<span></span>`)
+	}
+	fmt.Fprint(w, `</div>`)
 }
 
-func headerHtml(w http.ResponseWriter) {
+func showFileIndexHtml(w io.Writer, page pageOptions) {
+	fields := getBooleanFields()
+	files, _, project := computeProgress(fields)
+
+	writeHeaderHtml(w, page)
+	fmt.Fprint(w, `<div class="content">`)
+	fmt.Fprint(w, "<h3>📄 Project Files</h3>")
+	fmt.Fprint(w, `<div class="file-section"><table class="report">`)
+	fmt.Fprint(w, `<tr><th>File</th><th>Methods</th>`)
+	for _, field := range fields {
+		fmt.Fprint(w, `<th>`+parseEscapeHTML(field.Name)+`</th>`)
+	}
+	fmt.Fprint(w, `</tr>`)
+
+	for _, row := range files {
+		fmt.Fprint(w, `<tr><td><a href="`+parseEscapeHTML(page.FileLink(row.Name))+`">`+parseEscapeHTML(row.Name)+`</a></td>`)
+		fmt.Fprintf(w, `<td>%d</td>`, row.Methods)
+		for _, field := range fields {
+			fmt.Fprint(w, `<td>`+getProgressHtml(row.Progress[field.Name])+`</td>`)
+		}
+		fmt.Fprint(w, `</tr>`)
+	}
+
+	fmt.Fprintf(w, `<tr><th>Total</th><th>%d</th>`, project.Methods)
+	for _, field := range fields {
+		fmt.Fprint(w, `<th>`+getProgressHtml(project.Progress[field.Name])+`</th>`)
+	}
+	fmt.Fprint(w, `</tr></table></div>`)
+	fmt.Fprint(w, `</div></div>`)
+	writeFooterHtml(w, page)
+}
+
+func headerHtml(w io.Writer) {
+	writeHeaderHtml(w, serverPage)
+}
+
+func footerHtml(w io.Writer) {
+	writeFooterHtml(w, serverPage)
+}
+
+func writeHeaderHtml(w io.Writer, page pageOptions) {
+	var nav string = ""
+	var folder string = ""
+	if !page.ReadOnly {
+		nav = getNavHtml() + getNoticesHtml()
+		folder = `<span>📁 ` + parseEscapeHTML(pathProject) + `</span>`
+	}
+
 	fmt.Fprintf(w, `
 	<!DOCTYPE html>
 		<html data-theme="dark">
 			<head>
 			<meta charset="UTF-8">
-			<title>`+configProject.ProjectName+`</title>
+			<title>%s</title>
 			</head>
 			<style>
 			* {
@@ -149,7 +365,7 @@ func headerHtml(w http.ResponseWriter) {
 			}
 			
 			code {
-				font-family: 'Fira Code', 'Consolas', 'Monaco', 'Courier New', monospace;
+				font-family: 'Fira Mono', 'Consolas', 'Monaco', 'Courier New', monospace;
 				font-size: 0.95em;
 				line-height: 1.6;
 			}
@@ -171,10 +387,240 @@ func headerHtml(w http.ResponseWriter) {
 				background: rgba(74, 222, 128, 0.1);
 				border-radius: 6px;
 				border-left: 4px solid #4ade80;
-				font-family: 'Fira Code', 'Consolas', monospace;
+				font-family: 'Fira Mono', 'Consolas', monospace;
 				word-break: break-all;
 			}
 			
+			nav {
+				display: flex;
+				gap: 20px;
+				margin-top: 12px;
+				font-weight: 600;
+			}
+
+			nav .search {
+				margin-left: auto;
+			}
+
+			nav .reviewer {
+				color: #a0a0a0;
+			}
+
+			.field .conflict {
+				margin-top: 8px;
+				padding: 10px;
+				background: rgba(251, 191, 36, 0.1);
+				border-left: 4px solid #fbbf24;
+				border-radius: 6px;
+				color: #fbbf24;
+			}
+
+			.field .conflict pre {
+				white-space: pre-wrap;
+				color: #e4e4e4;
+				margin: 6px 0;
+			}
+
+			.field .conflict button {
+				margin-right: 6px;
+				cursor: pointer;
+			}
+
+			.field .attribution {
+				margin-top: 4px;
+				font-size: 0.8em;
+				color: #a0a0a0;
+			}
+
+			nav .search input {
+				background-color: rgba(30, 30, 30, 0.8);
+				color: #e4e4e4;
+				padding: 4px 10px;
+				border: 1px solid rgba(255, 255, 255, 0.2);
+				border-radius: 6px;
+			}
+
+			.fields .history {
+				margin-top: 10px;
+				font-size: 0.85em;
+				color: #a0a0a0;
+			}
+
+			.fields .history summary {
+				cursor: pointer;
+			}
+
+			.fields .history-list {
+				max-height: 200px;
+				overflow-y: auto;
+				margin-top: 6px;
+				white-space: pre-wrap;
+			}
+
+			pre.snippet {
+				margin: 0;
+				padding: 12px;
+				background: rgba(0, 0, 0, 0.3);
+				border-radius: 6px;
+				overflow-x: auto;
+				font-family: 'Fira Mono', 'Courier New', monospace;
+			}
+
+			pre.snippet .hit {
+				color: #fff;
+				font-weight: 600;
+			}
+
+			pre.snippet mark {
+				background: rgba(251, 191, 36, 0.4);
+				color: inherit;
+			}
+
+			.pair {
+				display: flex;
+				gap: 20px;
+			}
+
+			.pair > .legacy, .pair > .target {
+				flex: 1;
+				min-width: 0;
+			}
+
+			.target-file {
+				color: #a0a0a0;
+				font-family: 'Courier New', monospace;
+				margin-bottom: 8px;
+			}
+
+			.not-migrated {
+				color: #f87171;
+				font-weight: 600;
+			}
+
+			.summary {
+				display: flex;
+				flex-wrap: wrap;
+				gap: 30px;
+			}
+
+			.summary-field {
+				flex: 1;
+				min-width: 250px;
+			}
+
+			.summary-field h4 {
+				margin: 0 0 15px 0;
+				font-size: 1.3em;
+			}
+
+			.progress {
+				background: rgba(255, 255, 255, 0.1);
+				border-radius: 4px;
+				height: 8px;
+				min-width: 120px;
+				overflow: hidden;
+				margin-bottom: 4px;
+			}
+
+			.progress > .bar {
+				background: linear-gradient(135deg, #4ade80 0%%, #22c55e 100%%);
+				height: 100%%;
+			}
+
+			table.sortable th {
+				cursor: pointer;
+				user-select: none;
+			}
+
+			table.sortable th[data-order="asc"]::after {
+				content: " ▲";
+			}
+
+			table.sortable th[data-order="desc"]::after {
+				content: " ▼";
+			}
+
+			.file-nav {
+				display: flex;
+				justify-content: space-between;
+				gap: 20px;
+				margin: 20px 0;
+				font-weight: 600;
+			}
+
+			.notice {
+				margin-top: 12px;
+				font-weight: 600;
+			}
+
+			.notice a {
+				color: #fbbf24;
+			}
+
+			.fields > .method.old {
+				color: #f87171;
+				background: rgba(248, 113, 113, 0.1);
+				border-left-color: #f87171;
+				text-decoration: line-through;
+			}
+
+			table.report {
+				width: 100%%;
+				border-collapse: collapse;
+				margin-bottom: 20px;
+			}
+
+			table.report th, table.report td {
+				padding: 8px 12px;
+				border-bottom: 1px solid rgba(255, 255, 255, 0.1);
+				text-align: left;
+				vertical-align: top;
+			}
+
+			table.report th {
+				color: #fff;
+				background: rgba(102, 126, 234, 0.15);
+			}
+
+			.actions input {
+				background-color: rgba(30, 30, 30, 0.8);
+				color: #e4e4e4;
+				padding: 10px 12px;
+				border: 1px solid rgba(255, 255, 255, 0.2);
+				border-radius: 6px;
+				min-width: 300px;
+			}
+
+			.actions.inline {
+				display: inline-block;
+				margin: 2px 6px 2px 0;
+			}
+
+			.actions.inline button {
+				padding: 4px 10px;
+				font-size: 0.85em;
+			}
+
+			.actions button {
+				background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
+				color: white;
+				border: none;
+				padding: 10px 18px;
+				border-radius: 8px;
+				font-weight: 600;
+				cursor: pointer;
+			}
+
+			.fields > .needs-review {
+				color: #fbbf24;
+				font-weight: 600;
+				margin-bottom: 15px;
+				padding: 8px 10px;
+				background: rgba(251, 191, 36, 0.1);
+				border-radius: 6px;
+				border-left: 4px solid #fbbf24;
+			}
+
 			.field {
 				margin-bottom: 15px;
 			}
@@ -227,7 +673,20 @@ func headerHtml(w http.ResponseWriter) {
 				transition: all 0.2s ease;
 			}
 			
-			.field textarea:focus {
+			.field select {
+				background-color: rgba(30, 30, 30, 0.8);
+				color: #e4e4e4;
+				display: block;
+				width: 100%%;
+				padding: 10px 12px;
+				border: 1px solid rgba(255, 255, 255, 0.2);
+				border-radius: 6px;
+				font-family: inherit;
+				font-size: 0.95em;
+				cursor: pointer;
+			}
+
+			.field textarea:focus, .field select:focus {
 				outline: none;
 				border-color: #667eea;
 				box-shadow: 0 0 0 3px rgba(102, 126, 234, 0.2);
@@ -347,57 +806,215 @@ func headerHtml(w http.ResponseWriter) {
 					padding: 15px;
 				}
 			}
-			</style>
+			</style>`, parseEscapeHTML(configProject.ProjectName))
+
+	fmt.Fprint(w, `
 		<body>
-		<link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.8.0/styles/github-dark.min.css">
-		<link rel="preconnect" href="https://fonts.googleapis.com">
-		<link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
-		<link href="https://fonts.googleapis.com/css2?family=Fira+Code:wght@400;500;600&display=swap" rel="stylesheet">
-		<script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.8.0/highlight.min.js"></script>
+		<link rel="stylesheet" href="`+page.Root+`assets/github-dark.css">
+		<link rel="stylesheet" href="`+page.Root+`assets/fonts/fonts.css">
+		<script src="`+page.Root+`assets/highlight.min.js"></script>
+		<script src="`+page.Root+`assets/highlight-vb.js"></script>
 		<div class="container">
 			<header>
 				<h1 id="top">`+parseEscapeHTML(configProject.ProjectName)+`</h1>
-				<span>📁 `+parseEscapeHTML(pathProject)+`</span>
+				`+folder+`
+				`+nav+`
 			</header>
 			<div class="float-right">
-				`+getFilelistDropdownHtml()+`
+				`+getFilelistDropdownHtml(page)+`
 				<a href="#top" class="go-top-btn">⬆️ Go Top</a>
 			</div>`)
 }
 
-func footerHtml(w http.ResponseWriter) {
-	fmt.Fprintf(w, `<script>
-		hljs.highlightAll();
+func writeFooterHtml(w io.Writer, page pageOptions) {
+	fmt.Fprint(w, `<script>
+		document.querySelectorAll("pre code").forEach(function (block) {
+			if (hljs.highlightElement) {
+				hljs.highlightElement(block);
+			} else {
+				hljs.highlightBlock(block); // highlight.js 10
+			}
+		});
+		</script>`)
+
+	if !page.ReadOnly {
+		fmt.Fprint(w, `<script>
+
+		function getReviewer() {
+			var match = document.cookie.match(/(?:^|; )`+reviewerCookie+`=([^;]*)/);
+			return match ? decodeURIComponent(match[1]) : "";
+		}
+
+		function showReviewer() {
+			var link = document.getElementById("reviewer");
+			if (link) {
+				link.textContent = "👤 " + (getReviewer() || "Who are you?");
+			}
+		}
+
+		function changeReviewer() {
+			var name = prompt("Your name, shown next to your changes:", getReviewer());
+			if (name && name.trim()) {
+				document.cookie = "`+reviewerCookie+`=" + encodeURIComponent(name.trim()) + "; path=/; max-age=31536000; SameSite=Strict";
+				showReviewer();
+			}
+		}
+
+		showReviewer();
 
 		function saveChange(obj) {
+			if (document.getElementById("reviewer") && !getReviewer()) {
+				changeReviewer();
+			}
 			var name = obj.name;
 			var value = "";
 			if (obj.type == "checkbox") {
 				value = obj.checked ? 1 : 0;
-			} else if (obj.type == "textarea") {
+			} else if (obj.type == "textarea" || obj.type == "select-one") {
 				value = obj.value;
 			}
 			var xhttp = new XMLHttpRequest();
+			xhttp.onload = function () {
+				if (xhttp.status == 200) {
+					obj.dataset.version = JSON.parse(xhttp.responseText).version;
+				} else if (xhttp.status == 409) {
+					showConflict(obj, value, JSON.parse(xhttp.responseText));
+				}
+			};
 			xhttp.open("POST", "/save", true);
 			xhttp.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
-			xhttp.send("name="+name+"&value="+value);	
+			xhttp.send("name="+encodeURIComponent(name)+"&value="+encodeURIComponent(value)+"&version="+encodeURIComponent(obj.dataset.version));
+		}
+
+		function setFieldValue(obj, value) {
+			if (obj.type == "checkbox") {
+				obj.checked = value == "1";
+			} else {
+				obj.value = value;
+			}
+		}
+
+		// showConflict lets the reviewer choose between their change and the one
+		// saved by someone else since the page was loaded.
+		function showConflict(obj, mine, current) {
+			var field = obj.closest(".field");
+			var old = field.querySelector(".conflict");
+			if (old) {
+				old.remove();
+			}
+
+			var box = document.createElement("div");
+			box.className = "conflict";
+			var title = document.createElement("div");
+			title.textContent = "⚠️ Changed by " + (current.reviewer || "someone else") + " while you were editing:";
+			var theirs = document.createElement("pre");
+			theirs.textContent = obj.type == "checkbox" ? (current.value == "1" ? "checked" : "unchecked") : current.value;
+			box.appendChild(title);
+			box.appendChild(theirs);
+
+			function addButton(text, action) {
+				var button = document.createElement("button");
+				button.type = "button";
+				button.textContent = text;
+				button.onclick = function () {
+					obj.dataset.version = current.version;
+					box.remove();
+					action();
+				};
+				box.appendChild(button);
+			}
+			addButton("Keep theirs", function () {
+				setFieldValue(obj, current.value);
+			});
+			addButton("Keep mine", function () {
+				setFieldValue(obj, String(mine));
+				saveChange(obj);
+			});
+			if (obj.type == "textarea") {
+				addButton("Merge both", function () {
+					obj.value = current.value + "\n" + mine;
+					saveChange(obj);
+					obj.focus();
+				});
+			}
+			field.appendChild(box);
+		}
+
+		function loadHistory(details) {
+			if (!details.open) {
+				return;
+			}
+			var list = details.querySelector(".history-list");
+			list.textContent = "Loading...";
+			var xhttp = new XMLHttpRequest();
+			xhttp.onload = function () {
+				var entries = JSON.parse(xhttp.responseText);
+				list.textContent = entries.length == 0 ? "No changes yet" : "";
+				entries.reverse().forEach(function (entry) {
+					var row = document.createElement("div");
+					var when = new Date(entry.time).toLocaleString();
+					row.textContent = when + " · " + entry.reviewer + " · " + entry.field + ": \"" + entry.old + "\" → \"" + entry.new + "\"";
+					list.appendChild(row);
+				});
+			};
+			xhttp.open("GET", "/api/v1/history?file=" + encodeURIComponent(details.dataset.file) + "&method=" + encodeURIComponent(details.dataset.method), true);
+			xhttp.send();
 		}
+		</script>`)
+	}
 
-		</script>
+	fmt.Fprint(w, `
 		</div>
 		</body></html>`)
 }
 
-func getFilelistDropdownHtml() string {
+func getFilelistDropdownHtml(page pageOptions) string {
 	var html string = `<select onchange="location = this.value;">`
+	if page.Current == "" {
+		html += `<option value="" selected disabled>Go to file...</option>`
+	}
 	for _, filepath := range projectFiles {
 		filename := getFilename(filepath)
-		html += `<option value="#` + getFileID(filename) + `">` + parseEscapeHTML(filename) + `</option>`
+		html += `<option value="` + parseEscapeHTML(page.FileLink(filename)) + `"`
+		if filename == page.Current {
+			html += ` selected`
+		}
+		html += `>` + parseEscapeHTML(filename) + `</option>`
 	}
 	html += `</select>`
 	return html
 }
 
+func getNavHtml() string {
+	var html string = `<nav><a href="/">📄 Files</a><a href="/dashboard">📊 Dashboard</a><a href="/review">📝 Review</a>`
+	if pathTarget != "" {
+		html += `<a href="/migration">🔀 Migration</a>`
+		html += `<a href="/mappings">🔗 Mappings</a>`
+	}
+	html += `<form method="get" action="/search" class="search"><input type="search" name="q" placeholder="🔍 Search"></form>`
+	if defaultReviewer != "" {
+		html += `<span class="reviewer">👤 ` + parseEscapeHTML(defaultReviewer) + `</span>`
+	} else {
+		html += `<a href="#" id="reviewer" class="reviewer" onclick="changeReviewer(); return false;">👤 Who are you?</a>`
+	}
+	html += `</nav>`
+	return html
+}
+
+func getNoticesHtml() string {
+	var html string = ""
+	if proposals := len(getReanchorProposals()); proposals > 0 {
+		html += `<div class="notice"><a href="/reanchor">⚠️ ` + fmt.Sprint(proposals) + ` method header(s) changed, review them</a></div>`
+	}
+	if orphaned := getOrphanedCount(); orphaned > 0 {
+		html += `<div class="notice"><a href="/orphans">🗑️ ` + fmt.Sprint(orphaned) + ` orphaned field(s)</a></div>`
+	}
+	if conflicts := getMergeConflictsCount(); conflicts > 0 {
+		html += `<div class="notice"><a href="/conflicts">🔀 ` + fmt.Sprint(conflicts) + ` merge conflict(s) to resolve</a></div>`
+	}
+	return html
+}
+
 func showFilelistHtml(w http.ResponseWriter, filepath string) {
 	filename := getFilename(filepath)
 	fmt.Fprintf(w, `<a href="#`+filename+`">`+filename+`</a><br>`)
@@ -411,7 +1028,7 @@ func parseEscapeHTML(data string) string {
 	return html.EscapeString(data)
 }
 
-func showSourceHtml(w http.ResponseWriter, filepath string) {
+func showSourceHtml(w io.Writer, filepath string, page pageOptions) {
 	filename := getFilename(filepath)
 	fmt.Fprintf(w, `<div id="`+getFileID(filename)+`" class="mark"></div>`)
 	fmt.Fprintf(w, `<div class="file-section">`)
@@ -419,7 +1036,7 @@ func showSourceHtml(w http.ResponseWriter, filepath string) {
 
 	fmt.Fprintf(w, `<div class="collumns">`)
 	fmt.Fprintf(w, `<div class="codes">`)
-	fmt.Fprintf(w, filesData[filename].getContentHTMLWithFields())
+	fmt.Fprint(w, filesData[filename].getContentHtml(page.ReadOnly))
 	fmt.Fprintf(w, `</div></div></div>`)
 }
 
@@ -437,6 +1054,15 @@ func disassemblyFieldName(fieldName string) (string, string, string) {
 	return fields[0], fields[1], fields[2]
 }
 
+// saveResult is the answer to a change: the stored value, or the current one
+// when the change was rejected because someone else changed it first.
+type saveResult struct {
+	Error    string `json:"error,omitempty"`
+	Value    string `json:"value"`
+	Version  int    `json:"version"`
+	Reviewer string `json:"reviewer,omitempty"`
+}
+
 func saveHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -456,10 +1082,26 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !changedUserField(name, value) {
+	// Sin versión se guarda siempre, como antes
+	version := anyVersion
+	if r.Form.Has("version") {
+		var err error
+		version, err = strconv.Atoi(r.Form.Get("version"))
+		if err != nil || version < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	current, err := updateUserField(name, value, getRequestReviewer(r), version)
+	if err == errStaleValue {
+		writeJSON(w, http.StatusConflict, saveResult{err.Error(), current.Value, current.Version, current.Reviewer})
+		return
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	writeJSON(w, http.StatusOK, saveResult{"", current.Value, current.Version, current.Reviewer})
 }
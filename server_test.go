@@ -1,6 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -159,3 +168,194 @@ func TestParseEscapeHTML(t *testing.T) {
 		}
 	}
 }
+
+func TestHeaderHtmlOffline(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	headerHtml(recorder)
+	footerHtml(recorder)
+
+	page := recorder.Body.String()
+	for _, external := range []string{"http://", "https://", "//fonts.", "//cdnjs."} {
+		if strings.Contains(page, external) {
+			t.Errorf("Page should not reference external resources, found %s", external)
+		}
+	}
+}
+
+func TestAssetsHandler(t *testing.T) {
+	handler := assetsHandler()
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/assets/highlight.min.js", http.StatusOK},
+		{"/assets/highlight-vb.js", http.StatusOK},
+		{"/assets/github-dark.css", http.StatusOK},
+		{"/assets/fonts/fonts.css", http.StatusOK},
+		{"/assets/fonts/FiraMono-Regular.woff2", http.StatusOK},
+		{"/assets/missing.js", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if recorder.Code != test.expected {
+			t.Errorf("GET %s = %d; expected %d", test.path, recorder.Code, test.expected)
+		}
+	}
+}
+
+func TestGetFileURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"main.go", "/file/main.go"},
+		{"utils/helper.go", "/file/utils/helper.go"},
+		{"/main.go", "/file/main.go"},
+		{"dir/file with spaces.go", "/file/dir/file%20with%20spaces.go"},
+		{"dir/100%.go", "/file/dir/100%25.go"},
+	}
+
+	for _, test := range tests {
+		result := getFileURL(test.input)
+		if result != test.expected {
+			t.Errorf("getFileURL(%s) = %s; expected %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestFilePages(t *testing.T) {
+	// Setup
+	pathProject = "/test/project/"
+	configProject = config{
+		UserFields: []UserField{{Name: "Checked", Type: EnumBoolean}},
+	}
+	projectFiles = []string{"/test/project/a.go", "/test/project/b.go", "/test/project/c.go"}
+	filesData = make(map[string]fileData)
+	for _, filepath := range projectFiles {
+		filesData[getFilename(filepath)] = fileData{
+			Filename: filepath,
+			Content:  []string{"func " + strings.TrimSuffix(getFilename(filepath), ".go") + "() {", "}"},
+			Methods:  []int{0},
+		}
+	}
+	setUserFields(make([]fieldsData, 0))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", handler)
+	mux.HandleFunc("/file/", fileHandler)
+
+	tests := []struct {
+		path     string
+		expected int
+		contains []string
+		excludes []string
+	}{
+		{"/", http.StatusOK, []string{`href="/file/a.go"`, `href="/file/c.go"`}, []string{"func a()"}},
+		{"/file/b.go", http.StatusOK, []string{"func b()", `href="/file/a.go">⬅️ a.go`, `href="/file/c.go">c.go ➡️`, `value="/file/b.go" selected`}, []string{"func a()", "func c()"}},
+		{"/file/a.go", http.StatusOK, []string{"func a()", `c.go`}, []string{"⬅️ "}},
+		{"/file/missing.go", http.StatusNotFound, nil, nil},
+		{"/missing", http.StatusNotFound, nil, nil},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if recorder.Code != test.expected {
+			t.Errorf("GET %s = %d; expected %d", test.path, recorder.Code, test.expected)
+			continue
+		}
+		body := recorder.Body.String()
+		for _, expected := range test.contains {
+			if !strings.Contains(body, expected) {
+				t.Errorf("GET %s should contain %s", test.path, expected)
+			}
+		}
+		for _, unexpected := range test.excludes {
+			if strings.Contains(body, unexpected) {
+				t.Errorf("GET %s should not contain %s", test.path, unexpected)
+			}
+		}
+	}
+}
+
+func TestSaveHandlerConflict(t *testing.T) {
+	setupHistoryProject(t)
+	name := pathProject + "main.go<>func a() {<>Notes"
+	configProject.UserFields = append(configProject.UserFields, UserField{Name: "Notes", Type: EnumTextBox})
+
+	save := func(value string, version string, reviewer string) *httptest.ResponseRecorder {
+		body := "name=" + url.QueryEscape(name) + "&value=" + url.QueryEscape(value)
+		if version != "" {
+			body += "&version=" + version
+		}
+		request := httptest.NewRequest(http.MethodPost, "/save", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.AddCookie(&http.Cookie{Name: reviewerCookie, Value: reviewer})
+		recorder := httptest.NewRecorder()
+		saveHandler(recorder, request)
+		return recorder
+	}
+
+	// Ana y Luis abren la página con la versión 0
+	if recorder := save("from Ana", "0", "Ana"); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"version":1`) {
+		t.Fatalf("First save = %d %s", recorder.Code, recorder.Body.String())
+	}
+	recorder := save("from Luis", "0", "Luis")
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("Stale save = %d; expected %d", recorder.Code, http.StatusConflict)
+	}
+	var conflict saveResult
+	if err := json.Unmarshal(recorder.Body.Bytes(), &conflict); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if conflict.Value != "from Ana" || conflict.Version != 1 || conflict.Reviewer != "Ana" {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+	if value := getUserValue(pathProject+"main.go", "func a() {", "Notes"); value != "from Ana" {
+		t.Errorf("Stale save overwrote the value: %q", value)
+	}
+
+	// Luis combina las notas con la versión actual
+	if recorder := save("from Ana\nfrom Luis", "1", "Luis"); recorder.Code != http.StatusOK {
+		t.Errorf("Merged save = %d", recorder.Code)
+	}
+	if recorder := save("no version", "", "Luis"); recorder.Code != http.StatusOK {
+		t.Errorf("Save without version = %d", recorder.Code)
+	}
+	if recorder := save("bad version", "x", "Luis"); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Save with invalid version = %d", recorder.Code)
+	}
+
+	content := filesData["main.go"].getContentHTMLWithFields()
+	if !strings.Contains(content, `data-version="3"`) {
+		t.Errorf("Expected the current version in the page, got %s", content)
+	}
+}
+
+func TestIsServerRunning(t *testing.T) {
+	pathProject = t.TempDir()
+	if isServerRunning() {
+		t.Error("Without lock the server should not be running")
+	}
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skipf("Cannot listen: %v", err)
+	}
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	if err := os.WriteFile(path.Join(pathProject, serverLockFilename), []byte(port+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !isServerRunning() {
+		t.Error("Expected the server to be running")
+	}
+
+	// Un lock viejo sin servidor no bloquea
+	listener.Close()
+	if isServerRunning() {
+		t.Error("A lock without server should be ignored")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+type fieldStats struct {
+	Name    string        `json:"name"`
+	Type    EnumFieldType `json:"type"`
+	Done    int           `json:"done"`
+	Total   int           `json:"total"`
+	Percent float64       `json:"percent"`
+}
+
+type projectStats struct {
+	Project string       `json:"project"`
+	Files   int          `json:"files"`
+	Methods int          `json:"methods"`
+	Fields  []fieldStats `json:"fields"`
+}
+
+func getProjectStats() projectStats {
+	_, _, project := computeProgress(configProject.UserFields)
+
+	stats := projectStats{
+		Project: configProject.ProjectName,
+		Files:   len(projectFiles),
+		Methods: project.Methods,
+		Fields:  make([]fieldStats, 0, len(configProject.UserFields)),
+	}
+	for _, field := range configProject.UserFields {
+		progress := project.Progress[field.Name]
+		stats.Fields = append(stats.Fields, fieldStats{
+			Name:    field.Name,
+			Type:    field.Type,
+			Done:    progress.Done,
+			Total:   progress.Total,
+			Percent: progress.percent(),
+		})
+	}
+	return stats
+}
+
+func writeStatsText(w io.Writer, stats projectStats) {
+	fmt.Fprintf(w, "%s: %d file(s), %d method(s)\n", stats.Project, stats.Files, stats.Methods)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FIELD\tTYPE\tDONE\tTOTAL\tPERCENT")
+	for _, field := range stats.Fields {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%.1f%%\n", field.Name, field.Type, field.Done, field.Total, field.Percent)
+	}
+	table.Flush()
+}
+
+func statsCommand(args []string) int {
+	var format string
+	var fieldName string
+	var minPercent float64
+
+	flags := newCommandFlags("stats")
+	flags.StringVar(&format, "format", "text", "output format: text or json")
+	flags.StringVar(&fieldName, "field", "", "boolean field checked against --min")
+	flags.Float64Var(&minPercent, "min", 0, "minimum completion percent of --field")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if format != "text" && format != "json" {
+		fmt.Fprintln(os.Stderr, "Invalid format:", format)
+		return 2
+	}
+
+	if !loadCommandProject() {
+		return 1
+	}
+
+	var threshold *fieldStats
+	stats := getProjectStats()
+	if fieldName != "" {
+		for i := range stats.Fields {
+			if stats.Fields[i].Name == fieldName && stats.Fields[i].Type == EnumBoolean {
+				threshold = &stats.Fields[i]
+			}
+		}
+		if threshold == nil {
+			fmt.Fprintln(os.Stderr, "Boolean field not found:", fieldName)
+			return 2
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		if err := encoder.Encode(stats); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding stats: %v\n", err)
+			return 1
+		}
+	} else {
+		writeStatsText(os.Stdout, stats)
+	}
+
+	if threshold != nil && threshold.Percent < minPercent {
+		fmt.Fprintf(os.Stderr, "%s completion %.1f%% is below %.1f%%\n", threshold.Name, threshold.Percent, minPercent)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupStatsProject(t *testing.T) string {
+	tmpDir := t.TempDir() + string(filepath.Separator)
+
+	testConfig := config{
+		ProjectName:  "Stats",
+		ExtFilter:    []string{".go"},
+		MethodFilter: []string{`func .*\(.*\).*{`},
+		UserFields: []UserField{
+			{Name: "Checked", Type: EnumBoolean},
+			{Name: "Notes", Type: EnumTextBox},
+		},
+	}
+	configData, _ := json.Marshal(testConfig)
+	if err := os.WriteFile(tmpDir+configFilename, configData, 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	source := "package main\nfunc a() {\n}\nfunc b() {\n}\nfunc c() {\n}\nfunc d() {\n}\n"
+	if err := os.WriteFile(tmpDir+"main.go", []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+
+	values := []fieldsData{
+		{Filename: tmpDir + "main.go", Method: "func a() {", Field: "Checked", Value: "1"},
+		{Filename: tmpDir + "main.go", Method: "func b() {", Field: "Checked", Value: "1"},
+		{Filename: tmpDir + "main.go", Method: "func c() {", Field: "Checked", Value: "0"},
+		{Filename: tmpDir + "main.go", Method: "func c() {", Field: "Notes", Value: "todo"},
+	}
+	valuesData, _ := json.Marshal(values)
+	if err := os.WriteFile(tmpDir+userFieldsFilename, valuesData, 0644); err != nil {
+		t.Fatalf("Failed to create user fields: %v", err)
+	}
+
+	return tmpDir
+}
+
+func TestGetProjectStats(t *testing.T) {
+	pathProject = setupStatsProject(t)
+	if !loadCommandProject() {
+		t.Fatal("loadCommandProject() failed")
+	}
+
+	stats := getProjectStats()
+	if stats.Files != 1 || stats.Methods != 4 || len(stats.Fields) != 2 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	if checked := stats.Fields[0]; checked.Done != 2 || checked.Total != 4 || checked.Percent != 50 {
+		t.Errorf("Unexpected Checked stats: %+v", checked)
+	}
+	if notes := stats.Fields[1]; notes.Done != 1 {
+		t.Errorf("Unexpected Notes stats: %+v", notes)
+	}
+
+	var output bytes.Buffer
+	writeStatsText(&output, stats)
+	if !strings.Contains(output.String(), "Checked  boolean  2     4      50.0%") {
+		t.Errorf("Unexpected text output:\n%s", output.String())
+	}
+}
+
+func TestStatsCommandThreshold(t *testing.T) {
+	projectPath := setupStatsProject(t)
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"--path", projectPath}, 0},
+		{[]string{"--path", projectPath, "--format", "json"}, 0},
+		{[]string{"--path", projectPath, "--field", "Checked", "--min", "50"}, 0},
+		{[]string{"--path", projectPath, "--field", "Checked", "--min", "75"}, 1},
+		{[]string{"--path", projectPath, "--field", "Notes", "--min", "10"}, 2}, // Not boolean
+		{[]string{"--path", projectPath, "--format", "xml"}, 2},
+	}
+
+	for _, test := range tests {
+		if result := statsCommand(test.args); result != test.expected {
+			t.Errorf("statsCommand(%v) = %d; expected %d", test.args, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// methodRef identifies a method by its file (relative to its root) and header
+type methodRef struct {
+	Filename string
+	Method   string
+}
+
+var (
+	pathTarget      string
+	targetFiles     []string
+	targetFilesData map[string]fileData
+	// Métodos del destino indexados por nombre normalizado
+	targetMethodsByName map[string][]methodRef
+)
+
+// getTargetConfig returns the filters of the target tree. Without a "target"
+// section in the config the project filters are used.
+func getTargetConfig() targetConfig {
+	if configProject.Target != nil {
+		return *configProject.Target
+	}
+	return targetConfig{
+		LangHighlight: configProject.LangHighlight,
+		ExtFilter:     configProject.ExtFilter,
+		MethodFilter:  configProject.MethodFilter,
+		Include:       configProject.Include,
+		Exclude:       configProject.Exclude,
+		GitIgnore:     configProject.GitIgnore,
+	}
+}
+
+func getTargetFilename(filepath string) string {
+	return relativeFilename(pathTarget, filepath)
+}
+
+func isTargetExtFilter(filename string) bool {
+	for _, ext := range getTargetConfig().ExtFilter {
+		if filepath.Ext(filename) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func loadTargetFileData(filename string) error {
+	regexes := targetMethodFilterRegexes
+	if configProject.Target == nil {
+		regexes = methodFilterRegexes
+	}
+
+	data, err := readFileData(filename, fileProfile{MethodRegexes: regexes})
+	if err != nil {
+		return err
+	}
+
+	targetFilesData[getTargetFilename(filename)] = data
+	return nil
+}
+
+// loadTarget scans the migrated tree given with --target, if any.
+func loadTarget() bool {
+	targetFiles = make([]string, 0)
+	targetFilesData = make(map[string]fileData)
+	targetMethodsByName = make(map[string][]methodRef)
+
+	if pathTarget == "" {
+		return true
+	}
+
+	fmt.Fprintln(logOutput, "Target path:", pathTarget)
+
+	var err error
+
+	target := getTargetConfig()
+	filter := newScanFilter(pathTarget, target.Include, target.Exclude, target.GitIgnore)
+	targetFiles, err = scanTreeFiltered(pathTarget, nil, isTargetExtFilter, loadTargetFileData, filter)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error scanning target: %v\n", err)
+		return false
+	}
+	filter.logExclusions()
+
+	for _, filepath := range targetFiles {
+		filename := getTargetFilename(filepath)
+		data := targetFilesData[filename]
+		for _, line := range data.Methods {
+			name := normalizeMethodName(extractMethodName(data.Content[line]))
+			targetMethodsByName[name] = append(targetMethodsByName[name], methodRef{filename, data.Content[line]})
+		}
+	}
+
+	fmt.Fprintf(logOutput, "Target loaded: %d file(s) found\n", len(targetFiles))
+	return true
+}
+
+// getMigratedMethods returns the target methods a legacy method was migrated
+// to. Without explicit links the suggestions by name are returned and
+// suggested is true.
+func getMigratedMethods(legacyFilename string, legacyMethod string) (refs []methodRef, suggested bool) {
+	if refs = getLinkedMethods(legacyFilename, legacyMethod); len(refs) > 0 {
+		return refs, false
+	}
+	return getSuggestedMethods(legacyMethod), true
+}
+
+// getTargetMethodSegment returns the lines of a target method, or nil if it
+// doesn't exist.
+func getTargetMethodSegment(ref methodRef) []string {
+	data, ok := targetFilesData[ref.Filename]
+	if !ok {
+		return nil
+	}
+	for i, line := range data.Methods {
+		if data.Content[line] == ref.Method {
+			return data.getMethodSegment(i)
+		}
+	}
+	return nil
+}
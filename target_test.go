@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestLoadTarget(t *testing.T) {
+	// Create legacy and target trees
+	tmpDir := t.TempDir()
+	pathTarget = tmpDir + string(filepath.Separator)
+
+	testFiles := map[string]string{
+		"customers.go":      "package main\nfunc LoadCustomers() {\n}\nfunc SaveCustomers() {\n}",
+		"orders/orders.go":  "package orders\nfunc (o *Order) Load() {\n}",
+		"Form1.frm":         "Private Sub LoadCustomers()\nEnd Sub",
+		"vendor/lib/lib.go": "package lib\nfunc LoadCustomers() {\n}",
+		".gitignore":        "bin/\n",
+		"bin/tool.go":       "package main\nfunc LoadCustomers() {\n}",
+	}
+	for filePath, content := range testFiles {
+		fullPath := filepath.Join(tmpDir, filePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", fullPath, err)
+		}
+	}
+
+	// Setup config
+	configProject = config{
+		LangHighlight: "vb",
+		ExtFilter:     []string{".frm"},
+		MethodFilter:  []string{"Sub (.*)"},
+		Target: &targetConfig{
+			LangHighlight: "go",
+			ExtFilter:     []string{".go"},
+			MethodFilter:  []string{`func (\(.*\) )?.*\(.*\).*{`},
+			Exclude:       []string{"vendor/"},
+			GitIgnore:     true,
+		},
+	}
+	targetMethodFilterRegexes = []*regexp.Regexp{regexp.MustCompile(configProject.Target.MethodFilter[0])}
+
+	if !loadTarget() {
+		t.Fatal("loadTarget() failed")
+	}
+
+	if len(targetFiles) != 2 {
+		t.Errorf("Expected 2 target files, got %d", len(targetFiles))
+	}
+
+	refs, suggested := getMigratedMethods("Form1.frm", "Private Sub LoadCustomers()")
+	if !suggested || len(refs) != 1 || refs[0].Filename != "customers.go" || refs[0].Method != "func LoadCustomers() {" {
+		t.Fatalf("Unexpected migrated methods: %+v", refs)
+	}
+
+	segment := getTargetMethodSegment(refs[0])
+	if len(segment) != 2 {
+		t.Errorf("Expected 2 lines in target method, got %d: %v", len(segment), segment)
+	}
+
+	if refs, _ := getMigratedMethods("Form1.frm", "Private Sub Unknown()"); len(refs) != 0 {
+		t.Errorf("Expected no migrated methods, got %+v", refs)
+	}
+
+	pathTarget = ""
+}
+
+func TestGetTargetConfigFallback(t *testing.T) {
+	configProject = config{
+		LangHighlight: "go",
+		ExtFilter:     []string{".go"},
+	}
+
+	target := getTargetConfig()
+	if target.LangHighlight != "go" || len(target.ExtFilter) != 1 {
+		t.Errorf("Expected project filters as fallback, got %+v", target)
+	}
+}
@@ -10,20 +10,100 @@ import (
 )
 
 type fieldsData struct {
-	Filename string
-	Method   string
-	Field    string
-	Value    string
+	Filename    string
+	Method      string
+	Field       string
+	Value       string
+	Hash        string     `json:",omitempty"`
+	NeedsReview bool       `json:",omitempty"`
+	Reviewer    string     `json:",omitempty"`
+	UpdatedAt   *time.Time `json:",omitempty"`
+	Version     int        `json:",omitempty"` // Aumenta con cada cambio del valor
 }
 
+// anyVersion skips the version check of setUserValueBy.
+const anyVersion = -1
+
+// userFieldsIndex maps file → method → field to the position of the value in
+// userFields, which keeps the order of the JSON file.
+type userFieldsIndex map[string]map[string]map[string]int
+
 var (
 	userFieldsMutex sync.Mutex
+	userFieldsByKey userFieldsIndex
 )
 
+// setUserFields replaces all the stored values and rebuilds the index.
+func setUserFields(fields []fieldsData) {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	userFields = fields
+	rebuildUserFieldsIndex()
+}
+
+// rebuildUserFieldsIndex must be called with userFieldsMutex locked after
+// removing or moving values in userFields.
+func rebuildUserFieldsIndex() {
+	userFieldsByKey = make(userFieldsIndex)
+	for i := range userFields {
+		userFieldsByKey.add(userFields[i], i)
+	}
+	resetOrphanedCount()
+}
+
+func (index userFieldsIndex) add(userField fieldsData, position int) {
+	methods, ok := index[userField.Filename]
+	if !ok {
+		methods = make(map[string]map[string]int)
+		index[userField.Filename] = methods
+	}
+	fields, ok := methods[userField.Method]
+	if !ok {
+		fields = make(map[string]int)
+		methods[userField.Method] = fields
+	}
+	if _, exists := fields[userField.Field]; !exists {
+		fields[userField.Field] = position // Si hay duplicados gana el primero
+	}
+}
+
+func (index userFieldsIndex) find(filename string, method string, field string) (int, bool) {
+	position, ok := index[filename][method][field]
+	return position, ok
+}
+
+// methodPositions returns the positions of all the values of a method.
+func (index userFieldsIndex) methodPositions(filename string, method string) map[string]int {
+	return index[filename][method]
+}
+
+// saveRequests wakes up waitToSave when the values must be saved on every change.
+var saveRequests = make(chan struct{}, 1)
+
+// markChanged records a change of the user fields to be saved by waitToSave.
+func markChanged() {
+	lastChange = time.Now()
+	resetOrphanedCount()
+	if saveInterval == 0 {
+		select {
+		case saveRequests <- struct{}{}:
+		default: // Ya hay un guardado pendiente
+		}
+	}
+}
+
 func waitToSave() {
-	c := time.Tick(time.Second * 30)
+	if saveInterval == 0 {
+		for range saveRequests {
+			saveFileUserFields()
+		}
+		return
+	}
+
+	c := time.Tick(saveInterval)
 	for range c {
-		if time.Since(lastSave) > time.Second*30 {
+		if time.Since(lastSave) > saveInterval {
 			saveFileUserFields()
 		}
 	}
@@ -33,70 +113,229 @@ func setUserValue(filename string, method string, field string, value string) {
 	userFieldsMutex.Lock()
 	defer userFieldsMutex.Unlock()
 
-	found := false
+	storeUserValue(filename, method, field, value)
+}
 
-	for i := range userFields {
-		if userFields[i].Filename == filename &&
-			userFields[i].Method == method &&
-			userFields[i].Field == field {
-			userFields[i].Value = value
-			found = true
-			break
-		}
+// setUserValueBy sets a value recording who changed it and when. Unless
+// version is anyVersion, the value is only changed if it still has the version
+// the reviewer saw; otherwise the current value is returned with ok false.
+func setUserValueBy(filename string, method string, field string, value string, reviewer string, when time.Time, version int) (oldValue string, current fieldsData, ok bool) {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	if i, found := userFieldsByKey.find(filename, method, field); found {
+		current = userFields[i]
 	}
+	if version != anyVersion && version != current.Version {
+		return current.Value, current, false
+	}
+
+	i := storeUserValue(filename, method, field, value)
+	userFields[i].Reviewer = reviewer
+	userFields[i].UpdatedAt = &when
+	return current.Value, userFields[i], true
+}
 
-	if !found {
-		userFields = append(userFields, fieldsData{
-			Filename: filename,
-			Method:   method,
-			Field:    field,
-			Value:    value,
-		})
+// storeUserValue must be called with userFieldsMutex locked. Returns the
+// position of the value in userFields.
+func storeUserValue(filename string, method string, field string, value string) int {
+	if i, ok := userFieldsByKey.find(filename, method, field); ok {
+		userFields[i].Value = value
+		userFields[i].Version++
+		return i
 	}
+
+	userField := fieldsData{
+		Filename: filename,
+		Method:   method,
+		Field:    field,
+		Value:    value,
+		Version:  1,
+	}
+	if userFieldsByKey == nil {
+		userFieldsByKey = make(userFieldsIndex)
+	}
+	userFieldsByKey.add(userField, len(userFields))
+	userFields = append(userFields, userField)
+	return len(userFields) - 1
 }
 
 func getUserValue(filename string, method string, field string) string {
 	userFieldsMutex.Lock()
 	defer userFieldsMutex.Unlock()
 
+	if i, ok := userFieldsByKey.find(filename, method, field); ok {
+		return userFields[i].Value
+	}
+	return ""
+}
+
+// getUserField returns the stored value of a field with its attribution.
+func getUserField(filename string, method string, field string) (fieldsData, bool) {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	if i, ok := userFieldsByKey.find(filename, method, field); ok {
+		return userFields[i], true
+	}
+	return fieldsData{}, false
+}
+
+// clearUserValue empties the value of a field. The entry is kept with a new
+// version, so a change made on the old value is still rejected. Returns false
+// if there was no value to remove.
+func clearUserValue(filename string, method string, field string) bool {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	i, ok := userFieldsByKey.find(filename, method, field)
+	if !ok || userFields[i].Value == "" {
+		return false
+	}
+	userFields[i].Value = ""
+	userFields[i].Version++
+	return true
+}
+
+// markMethodReviewed stores the current content hash of a method in all its
+// fields and clears the "needs re-review" flag.
+func markMethodReviewed(filename string, method string, hash string) {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	for _, i := range userFieldsByKey.methodPositions(filename, method) {
+		userFields[i].Hash = hash
+		userFields[i].NeedsReview = false
+	}
+}
+
+// setUserMethodHash stores the current content hash of a method in all its
+// fields, keeping the "needs re-review" flag.
+func setUserMethodHash(filename string, method string, hash string) {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	for _, i := range userFieldsByKey.methodPositions(filename, method) {
+		userFields[i].Hash = hash
+	}
+}
+
+// renameUserMethod moves the fields of a method to a new header. Fields that
+// already have a value in the new header are kept and the old ones dropped.
+func renameUserMethod(filename string, oldMethod string, newMethod string) {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	existing := userFieldsByKey.methodPositions(filename, newMethod)
+
+	renamed := make([]fieldsData, 0, len(userFields))
 	for _, userField := range userFields {
-		if userField.Filename == filename &&
-			userField.Method == method &&
-			userField.Field == field {
-			return userField.Value
+		if userField.Filename == filename && userField.Method == oldMethod {
+			if _, ok := existing[userField.Field]; ok {
+				continue
+			}
+			userField.Method = newMethod
+			userField.Version++
 		}
+		renamed = append(renamed, userField)
 	}
-	return ""
+	userFields = renamed
+	rebuildUserFieldsIndex()
+}
+
+func methodNeedsReview(filename string, method string) bool {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	for _, i := range userFieldsByKey.methodPositions(filename, method) {
+		if userFields[i].NeedsReview {
+			return true
+		}
+	}
+	return false
 }
 
+func countMethodsNeedingReview() int {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	methods := make(map[string]bool)
+	for _, userField := range userFields {
+		if userField.NeedsReview {
+			methods[userField.Filename+`<>`+userField.Method] = true
+		}
+	}
+	return len(methods)
+}
+
+// checkReviewChanges compares the stored hashes against the loaded files and
+// flags the methods whose body changed. Returns the number of fields updated.
+func checkReviewChanges() int {
+	userFieldsMutex.Lock()
+	defer userFieldsMutex.Unlock()
+
+	updated := 0
+	for i := range userFields {
+		hash := getMethodHash(userFields[i].Filename, userFields[i].Method)
+		if hash == "" {
+			continue // Archivo o método inexistente
+		}
+		if userFields[i].Hash == "" {
+			userFields[i].Hash = hash // Datos anteriores sin hash
+			updated++
+		} else if userFields[i].Hash != hash && !userFields[i].NeedsReview {
+			userFields[i].NeedsReview = true
+			updated++
+		}
+	}
+	return updated
+}
+
+// loadUserFields reads the stored values. If the file is damaged the backup of
+// the previous save is used; if both fail the load is aborted so the next save
+// does not overwrite the review data with an empty list.
 func loadUserFields() bool {
 	userFieldsPath := path.Join(pathProject, userFieldsFilename)
-	if !isValidFile(userFieldsPath) {
-		fmt.Println("User fields file not found, starting with empty fields")
-		userFields = make([]fieldsData, 0)
+	backupPath := userFieldsPath + ".bak"
+	if !isValidFile(userFieldsPath) && !isValidFile(backupPath) {
+		fmt.Fprintln(logOutput, "User fields file not found, starting with empty fields")
+		setUserFields(make([]fieldsData, 0))
 		return true
 	}
 
-	userFieldsData, err := os.Open(userFieldsPath)
+	fields, err := readUserFieldsFile(userFieldsPath)
 	if err != nil {
-		fmt.Printf("Error opening user fields file: %v\n", err)
-		userFields = make([]fieldsData, 0)
-		return true // Continuar sin campos de usuario
+		fmt.Fprintf(logOutput, "Error reading user fields file: %v\n", err)
+		if !isValidFile(backupPath) {
+			return false
+		}
+
+		fmt.Fprintf(logOutput, "Loading backup %s\n", backupPath)
+		fields, err = readUserFieldsFile(backupPath)
+		if err != nil {
+			fmt.Fprintf(logOutput, "Error reading user fields backup: %v\n", err)
+			return false
+		}
+		markChanged() // Reescribir el archivo dañado en el próximo guardado
 	}
-	defer userFieldsData.Close()
+	setUserFields(fields)
 
-	userFields = make([]fieldsData, 0)
+	fmt.Fprintf(logOutput, "User fields loaded: %d field(s)\n", len(userFields))
+	return true
+}
 
-	decoder := json.NewDecoder(userFieldsData)
-	err = decoder.Decode(&userFields)
+func readUserFieldsFile(filename string) ([]fieldsData, error) {
+	f, err := os.Open(filename)
 	if err != nil {
-		fmt.Printf("Error decoding user fields file: %v\n", err)
-		userFields = make([]fieldsData, 0)
-		return true // Continuar sin campos de usuario
+		return nil, err
 	}
+	defer f.Close()
 
-	fmt.Printf("User fields loaded: %d field(s)\n", len(userFields))
-	return true
+	fields := make([]fieldsData, 0)
+	if err := json.NewDecoder(f).Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
 }
 
 func saveFileUserFields() {
@@ -107,30 +346,17 @@ func saveFileUserFields() {
 		return // Sin cambios, no hay nada que guardar
 	}
 
-	userFieldsPath := path.Join(pathProject, userFieldsFilename)
-	
-	// Eliminar archivo existente si existe
-	if _, err := os.Stat(userFieldsPath); err == nil {
-		if err := os.Remove(userFieldsPath); err != nil {
-			fmt.Printf("Warning: error removing old user fields file: %v\n", err)
-		}
-	}
-
-	f, err := os.Create(userFieldsPath)
+	data, err := json.MarshalIndent(userFields, "", "    ")
 	if err != nil {
-		fmt.Printf("Error creating user fields file: %v\n", err)
+		fmt.Fprintf(logOutput, "Error encoding user fields: %v\n", err)
 		return
 	}
-	defer f.Close()
 
-	encoder := json.NewEncoder(f)
-	encoder.SetIndent("", "    ")
-	err = encoder.Encode(userFields)
-	if err != nil {
-		fmt.Printf("Error encoding user fields: %v\n", err)
+	if err := writeFileAtomic(path.Join(pathProject, userFieldsFilename), append(data, '\n')); err != nil {
+		fmt.Fprintf(logOutput, "Error saving user fields file: %v\n", err)
 		return
 	}
 
 	lastSave = time.Now()
-	fmt.Printf("User fields saved: %d field(s)\n", len(userFields))
+	fmt.Fprintf(logOutput, "User fields saved: %d field(s)\n", len(userFields))
 }
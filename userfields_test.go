@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -11,7 +12,7 @@ import (
 
 func TestSetUserValue(t *testing.T) {
 	// Reset userFields before test
-	userFields = make([]fieldsData, 0)
+	setUserFields(make([]fieldsData, 0))
 
 	// Test setting new value
 	setUserValue("file.go", "main", "checked", "1")
@@ -47,11 +48,11 @@ func TestSetUserValue(t *testing.T) {
 
 func TestGetUserValue(t *testing.T) {
 	// Reset and setup test data
-	userFields = []fieldsData{
-		{"file.go", "main", "checked", "1"},
-		{"file.go", "main", "notes", "test notes"},
-		{"utils.go", "helper", "checked", "0"},
-	}
+	setUserFields([]fieldsData{
+		{Filename: "file.go", Method: "main", Field: "checked", Value: "1"},
+		{Filename: "file.go", Method: "main", Field: "notes", Value: "test notes"},
+		{Filename: "utils.go", Method: "helper", Field: "checked", Value: "0"},
+	})
 
 	// Test getting existing values
 	tests := []struct {
@@ -82,7 +83,7 @@ func TestGetUserValue(t *testing.T) {
 
 func TestConcurrentUserFieldAccess(t *testing.T) {
 	// Reset userFields
-	userFields = make([]fieldsData, 0)
+	setUserFields(make([]fieldsData, 0))
 
 	// Test concurrent access to userFields
 	var wg sync.WaitGroup
@@ -131,8 +132,8 @@ func TestLoadUserFields(t *testing.T) {
 	// Create test user fields file
 	testFile := filepath.Join(tmpDir, "zoomer-userfields.json")
 	testData := []fieldsData{
-		{"file.go", "main", "checked", "1"},
-		{"utils.go", "helper", "notes", "test notes"},
+		{Filename: "file.go", Method: "main", Field: "checked", Value: "1"},
+		{Filename: "utils.go", Method: "helper", Field: "notes", Value: "test notes"},
 	}
 
 	// Write test data to file
@@ -150,7 +151,7 @@ func TestLoadUserFields(t *testing.T) {
 	}
 
 	// Reset userFields
-	userFields = make([]fieldsData, 0)
+	setUserFields(make([]fieldsData, 0))
 
 	// Test loading
 	result := loadUserFields()
@@ -174,10 +175,10 @@ func TestSaveFileUserFields(t *testing.T) {
 	pathProject = tmpDir
 
 	// Setup test data
-	userFields = []fieldsData{
-		{"file.go", "main", "checked", "1"},
-		{"utils.go", "helper", "notes", "test notes"},
-	}
+	setUserFields([]fieldsData{
+		{Filename: "file.go", Method: "main", Field: "checked", Value: "1"},
+		{Filename: "utils.go", Method: "helper", Field: "notes", Value: "test notes"},
+	})
 
 	// Reset timestamps
 	lastChange = time.Now()
@@ -222,9 +223,9 @@ func TestSaveFileUserFieldsNoChanges(t *testing.T) {
 	pathProject = tmpDir
 
 	// Setup test data
-	userFields = []fieldsData{
-		{"file.go", "main", "checked", "1"},
-	}
+	setUserFields([]fieldsData{
+		{Filename: "file.go", Method: "main", Field: "checked", Value: "1"},
+	})
 
 	// Set timestamps so no save is needed
 	now := time.Now()
@@ -240,3 +241,265 @@ func TestSaveFileUserFieldsNoChanges(t *testing.T) {
 		t.Error("User fields file was created when it shouldn't have been")
 	}
 }
+
+func TestCheckReviewChanges(t *testing.T) {
+	// Setup
+	pathProject = "/test/project"
+	filesData = map[string]fileData{
+		"/file.go": {
+			Filename: "/test/project/file.go",
+			Content:  []string{"package main", "func main() {", "}"},
+			Methods:  []int{1},
+		},
+	}
+	hash := getMethodHash("/test/project/file.go", "func main() {")
+
+	setUserFields([]fieldsData{
+		{Filename: "/test/project/file.go", Method: "func main() {", Field: "Checked", Value: "1", Hash: hash},
+		{Filename: "/test/project/file.go", Method: "func main() {", Field: "Notes", Value: "ok"},
+		{Filename: "/test/project/gone.go", Method: "func gone() {", Field: "Checked", Value: "1", Hash: "old"},
+	})
+
+	// Unchanged method, legacy entry without hash gets one
+	if updated := checkReviewChanges(); updated != 1 {
+		t.Errorf("Expected 1 updated field, got %d", updated)
+	}
+	if userFields[1].Hash != hash {
+		t.Errorf("Expected legacy entry to adopt the current hash, got '%s'", userFields[1].Hash)
+	}
+	if methodNeedsReview("/test/project/file.go", "func main() {") {
+		t.Error("Unchanged method should not need review")
+	}
+
+	// Change the method body
+	filesData["/file.go"] = fileData{
+		Filename: "/test/project/file.go",
+		Content:  []string{"package main", "func main() {", "\tpanic(1)", "}"},
+		Methods:  []int{1},
+	}
+
+	if updated := checkReviewChanges(); updated != 2 {
+		t.Errorf("Expected 2 updated fields, got %d", updated)
+	}
+	if !methodNeedsReview("/test/project/file.go", "func main() {") {
+		t.Error("Changed method should need review")
+	}
+	if methodNeedsReview("/test/project/gone.go", "func gone() {") {
+		t.Error("Missing file should not be flagged")
+	}
+	if count := countMethodsNeedingReview(); count != 1 {
+		t.Errorf("Expected 1 method needing review, got %d", count)
+	}
+
+	// Saving a field marks the method as reviewed again
+	changedUserField("/test/project/file.go<>func main() {<>Checked", "1", "")
+	if methodNeedsReview("/test/project/file.go", "func main() {") {
+		t.Error("Method should not need review after saving a field")
+	}
+}
+
+func TestUserFieldsIndex(t *testing.T) {
+	setUserFields([]fieldsData{
+		{Filename: "file.go", Method: "a", Field: "checked", Value: "1"},
+		{Filename: "file.go", Method: "b", Field: "checked", Value: "1"},
+		{Filename: "file.go", Method: "b", Field: "notes", Value: "x"},
+		{Filename: "file.go", Method: "c", Field: "notes", Value: "y"},
+	})
+
+	// Borrar y renombrar mueven las posiciones, el índice debe seguir válido
+	if !clearUserValue("file.go", "a", "checked") {
+		t.Fatal("clearUserValue should remove an existing value")
+	}
+	if clearUserValue("file.go", "a", "checked") {
+		t.Error("clearUserValue should return false for a missing value")
+	}
+	renameUserMethod("file.go", "b", "c")
+	setUserValue("file.go", "d", "checked", "1")
+
+	tests := []struct {
+		method   string
+		field    string
+		expected string
+	}{
+		{"a", "checked", ""},
+		{"b", "checked", ""},
+		{"c", "checked", "1"},
+		{"c", "notes", "y"},
+		{"d", "checked", "1"},
+	}
+	for _, test := range tests {
+		if value := getUserValue("file.go", test.method, test.field); value != test.expected {
+			t.Errorf("getUserValue(%s, %s) = %q; expected %q", test.method, test.field, value, test.expected)
+		}
+	}
+	if len(userFields) != 4 {
+		t.Errorf("Expected 4 stored values, got %d", len(userFields))
+	}
+}
+
+func TestClearUserValueKeepsVersion(t *testing.T) {
+	setUserFields(nil)
+	_, seen, _ := setUserValueBy("file.go", "a", "notes", "first", "ana", time.Now(), anyVersion)
+
+	// Otro revisor borra y vuelve a escribir: la versión vista ya no vale
+	if !clearUserValue("file.go", "a", "notes") {
+		t.Fatal("clearUserValue should clear an existing value")
+	}
+	setUserValueBy("file.go", "a", "notes", "second", "luis", time.Now(), anyVersion)
+
+	if _, current, ok := setUserValueBy("file.go", "a", "notes", "stale", "ana", time.Now(), seen.Version); ok || current.Value != "second" {
+		t.Errorf("A change on a cleared and rewritten value should be rejected, got %+v", current)
+	}
+	if current, _ := getUserField("file.go", "a", "notes"); current.Version != seen.Version+2 {
+		t.Errorf("Expected version %d, got %d", seen.Version+2, current.Version)
+	}
+}
+
+func BenchmarkGetUserValue(b *testing.B) {
+	for _, size := range []int{1000, 10000, 40000} {
+		fields := make([]fieldsData, 0, size)
+		for i := 0; i < size; i++ {
+			fields = append(fields, fieldsData{Filename: "file.go", Method: fmt.Sprintf("func m%d() {", i), Field: "Checked", Value: "1"})
+		}
+		setUserFields(fields)
+
+		b.Run(fmt.Sprintf("values=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				getUserValue("file.go", fmt.Sprintf("func m%d() {", i%size), "Checked")
+			}
+		})
+	}
+}
+
+func TestSaveFileUserFieldsKeepsBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	pathProject = tmpDir
+	testFile := filepath.Join(tmpDir, userFieldsFilename)
+
+	setUserFields([]fieldsData{{Filename: "file.go", Method: "main", Field: "checked", Value: "1"}})
+	lastSave = time.Now().Add(-time.Minute)
+	lastChange = time.Now()
+	saveFileUserFields()
+
+	setUserValue("file.go", "main", "notes", "second save")
+	lastChange = time.Now().Add(time.Second)
+	saveFileUserFields()
+
+	current, err := readUserFieldsFile(testFile)
+	if err != nil || len(current) != 2 {
+		t.Fatalf("Expected 2 fields in saved file, got %d (%v)", len(current), err)
+	}
+	backup, err := readUserFieldsFile(testFile + ".bak")
+	if err != nil || len(backup) != 1 {
+		t.Fatalf("Expected the previous save in the backup, got %d (%v)", len(backup), err)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 2 {
+		t.Errorf("Temporary files left behind: %v", entries)
+	}
+}
+
+func TestLoadUserFieldsFallback(t *testing.T) {
+	valid, _ := json.Marshal([]fieldsData{{Filename: "file.go", Method: "main", Field: "checked", Value: "1"}})
+
+	tests := []struct {
+		name     string
+		main     string
+		backup   string
+		expected bool
+		fields   int
+	}{
+		{"valid file", string(valid), "", true, 1},
+		{"corrupt file with backup", `[{"Filename": "file.go"`, string(valid), true, 1},
+		{"missing file with backup", "", string(valid), true, 1},
+		{"corrupt file without backup", `[{"Filename": "file.go"`, "", false, 0},
+		{"corrupt file and backup", `not json`, `not json`, false, 0},
+	}
+
+	for _, test := range tests {
+		tmpDir := t.TempDir()
+		pathProject = tmpDir
+		testFile := filepath.Join(tmpDir, userFieldsFilename)
+		if test.main != "" {
+			os.WriteFile(testFile, []byte(test.main), 0644)
+		}
+		if test.backup != "" {
+			os.WriteFile(testFile+".bak", []byte(test.backup), 0644)
+		}
+		setUserFields(make([]fieldsData, 0))
+
+		if result := loadUserFields(); result != test.expected {
+			t.Errorf("%s: loadUserFields() = %v; expected %v", test.name, result, test.expected)
+		}
+		if len(userFields) != test.fields {
+			t.Errorf("%s: expected %d field(s), got %d", test.name, test.fields, len(userFields))
+		}
+	}
+}
+
+func TestSaveAfterFallbackKeepsBackup(t *testing.T) {
+	valid, _ := json.Marshal([]fieldsData{{Filename: "file.go", Method: "main", Field: "checked", Value: "1"}})
+	tmpDir := t.TempDir()
+	pathProject = tmpDir
+	testFile := filepath.Join(tmpDir, userFieldsFilename)
+	os.WriteFile(testFile, []byte(`[{"Filename": "file.go"`), 0644)
+	os.WriteFile(testFile+".bak", valid, 0644)
+
+	lastSave = time.Now().Add(-time.Minute)
+	if !loadUserFields() {
+		t.Fatal("loadUserFields should load the backup")
+	}
+	setUserValue("file.go", "main", "notes", "after fallback")
+	lastChange = time.Now().Add(time.Second)
+	saveFileUserFields()
+
+	backup, err := readUserFieldsFile(testFile + ".bak")
+	if err != nil || len(backup) != 1 {
+		t.Errorf("The corrupt file should not replace the good backup, got %d (%v)", len(backup), err)
+	}
+	if current, err := readUserFieldsFile(testFile); err != nil || len(current) != 2 {
+		t.Errorf("Expected 2 fields in saved file, got %d (%v)", len(current), err)
+	}
+}
+
+func TestSaveOnEveryChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	pathProject = tmpDir
+	saveInterval = 0
+	defer func() { saveInterval = defaultSaveInterval }()
+
+	setUserFields(make([]fieldsData, 0))
+	lastSave = time.Now().Add(-time.Minute)
+	go waitToSave()
+
+	setUserValue("file.go", "main", "checked", "1")
+	markChanged()
+
+	testFile := filepath.Join(tmpDir, userFieldsFilename)
+	deadline := time.Now().Add(5 * time.Second)
+	for !isValidFile(testFile) {
+		if time.Now().After(deadline) {
+			t.Fatal("User fields were not saved after the change")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSetUserValueByVersion(t *testing.T) {
+	setUserFields(make([]fieldsData, 0))
+	now := time.Now()
+
+	if _, current, ok := setUserValueBy("file.go", "main", "notes", "first", "Ana", now, 0); !ok || current.Version != 1 {
+		t.Fatalf("First change should be stored with version 1, got %+v", current)
+	}
+	if old, current, ok := setUserValueBy("file.go", "main", "notes", "stale", "Luis", now, 0); ok || old != "first" || current.Reviewer != "Ana" {
+		t.Errorf("Stale change should be rejected, got %v %+v", ok, current)
+	}
+	if old, current, ok := setUserValueBy("file.go", "main", "notes", "second", "Luis", now, 1); !ok || old != "first" || current.Version != 2 {
+		t.Errorf("Change with current version should be stored, got %v %+v", ok, current)
+	}
+	if _, current, ok := setUserValueBy("file.go", "main", "notes", "forced", "Luis", now, anyVersion); !ok || current.Version != 3 {
+		t.Errorf("Change without version check should be stored, got %v %+v", ok, current)
+	}
+}